@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"runtime"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/theriault/collatz/shared"
+)
+
+var (
+	accelCmd = &cobra.Command{
+		Use:   "accel",
+		Short: "Verify each registered acceleration of C reproduces f and compare its number of steps to f, g and h",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if power < 1 || power > 20 {
+				return fmt.Errorf("--k must be in the range 1..20: %d", power)
+			}
+			limit := uint64(math.Pow10(power))
+			name, err := cmd.Flags().GetString("map")
+			if err != nil {
+				return err
+			}
+			maps := make([]shared.Map, 0)
+			for _, m := range shared.Maps {
+				if name == "" || name == m.Name {
+					maps = append(maps, m)
+				}
+			}
+			if len(maps) == 0 {
+				return fmt.Errorf("invalid value for --map: %s", name)
+			}
+
+			log.Printf("computing f(x), g(x), h(x) from 1..10^%d...", power)
+			f := batched("f", limit)
+			sumF, sumG, sumH := sumStoppingTimes(limit, f, batched("g", limit), batched("h", limit))
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "map\tstep\tsteps\tstandard steps per step\tsteps / Σf\tsteps / Σg\tsteps / Σh\t")
+			failed := 0
+			for _, m := range maps {
+				log.Printf("verifying %s from 1..10^%d...", m.Name, power)
+				sum, mismatch := verifyMap(limit, m, f)
+				if mismatch.x != 0 {
+					fx := f(mismatch.x).Time
+					switch {
+					case mismatch.overflow:
+						fmt.Fprintf(w, "%s\t%s\tx = %d leaves the range of uint64 after %d standard steps, f(x) = %d\t\t\t\t\t\n", m.Name, m.Description, mismatch.x, mismatch.normalTime, fx)
+					case mismatch.normalTime > fx:
+						fmt.Fprintf(w, "%s\t%s\tx = %d does not reach 1 within %d standard steps, f(x) = %d\t\t\t\t\t\n", m.Name, m.Description, mismatch.x, mismatch.normalTime, fx)
+					default:
+						fmt.Fprintf(w, "%s\t%s\tx = %d covers %d standard steps, f(x) = %d\t\t\t\t\t\n", m.Name, m.Description, mismatch.x, mismatch.normalTime, fx)
+					}
+					failed++
+					continue
+				}
+				fmt.Fprintf(w, "%s\t%s\t%d\t%.4f\t%.4f\t%.4f\t%.4f\t\n", m.Name, m.Description, sum,
+					float64(sumF)/float64(sum), float64(sum)/float64(sumF), float64(sum)/float64(sumG), float64(sum)/float64(sumH))
+			}
+			w.Flush()
+			if failed > 0 {
+				return fmt.Errorf("%d maps do not reproduce f", failed)
+			}
+			return nil
+		},
+	}
+)
+
+func init() {
+	accelCmd.Flags().IntVar(&power, "k", 5, "examine n up to 10^k")
+	accelCmd.Flags().String("map", "", "name of the map to verify. leave blank for all")
+}
+
+func sumStoppingTimes(limit uint64, f, g, h func(n uint64) shared.Result) (uint64, uint64, uint64) {
+	var wg sync.WaitGroup
+	workers := uint64(runtime.GOMAXPROCS(0))
+	sums := make([][3]uint64, workers)
+	for w := uint64(0); w < workers; w++ {
+		wg.Add(1)
+		go (func(worker uint64, workerCount uint64, limit uint64) {
+			defer wg.Done()
+			for i := 1 + worker; i < limit; i += workerCount {
+				sums[worker][0] += f(i).Time
+				sums[worker][1] += g(i).Time
+				sums[worker][2] += h(i).Time
+			}
+		})(w, workers, limit)
+	}
+	wg.Wait()
+	total := [3]uint64{}
+	for _, s := range sums {
+		total[0] += s[0]
+		total[1] += s[1]
+		total[2] += s[2]
+	}
+	return total[0], total[1], total[2]
+}
+
+// mapMismatch is an x where the orbit under a map does not reproduce f(x), along with the standard steps covered by
+// the map before it reached 1, overflowed or went past f(x)
+type mapMismatch struct {
+	x          uint64
+	normalTime uint64
+	overflow   bool
+}
+
+// verifyMap returns the total number of steps of m from 1..limit, or the smallest x where the standard steps covered
+// by m differ from f(x) or the orbit under m leaves the range of uint64
+func verifyMap(limit uint64, m shared.Map, f func(n uint64) shared.Result) (uint64, mapMismatch) {
+	var wg sync.WaitGroup
+	workers := uint64(runtime.GOMAXPROCS(0))
+	sums := make([]uint64, workers)
+	mismatches := make([]mapMismatch, workers)
+	for w := uint64(0); w < workers; w++ {
+		wg.Add(1)
+		go (func(worker uint64, workerCount uint64, limit uint64) {
+			defer wg.Done()
+			for i := 1 + worker; i < limit; i += workerCount {
+				fx := f(i).Time
+				// follow the orbit no further than f(x) standard steps, in case the map never reaches 1
+				time, normalTime := uint64(0), uint64(0)
+				ok := true
+				for x := i; x != 1 && normalTime <= fx && ok; time++ {
+					var steps uint64
+					x, steps, ok = m.Step(x)
+					normalTime += steps
+				}
+				if !ok || normalTime != fx {
+					mismatches[worker] = mapMismatch{x: i, normalTime: normalTime, overflow: !ok}
+					return
+				}
+				sums[worker] += time
+			}
+		})(w, workers, limit)
+	}
+	wg.Wait()
+	sum := uint64(0)
+	mismatch := mapMismatch{}
+	for w := range sums {
+		sum += sums[w]
+		if mismatches[w].x != 0 && (mismatch.x == 0 || mismatches[w].x < mismatch.x) {
+			mismatch = mismatches[w]
+		}
+	}
+	return sum, mismatch
+}
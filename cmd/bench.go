@@ -0,0 +1,306 @@
+package cmd
+
+import (
+	"fmt"
+	"image/color"
+	"log"
+	"math"
+	"math/rand"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/theriault/collatz/shared"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+)
+
+var (
+	benchCmd = &cobra.Command{
+		Use:   "bench",
+		Short: "Measure the wall-clock cost of f, g, h and each registered acceleration of C per n and per standard step",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			from, err := cmd.Flags().GetUint64("from")
+			if err != nil {
+				return err
+			}
+			to, err := cmd.Flags().GetUint64("to")
+			if err != nil {
+				return err
+			}
+			if from < 1 || to <= from {
+				return fmt.Errorf("expected 1 <= --from < --to: %d, %d", from, to)
+			}
+			count, err := cmd.Flags().GetInt("count")
+			if err != nil {
+				return err
+			}
+			if count < 1 {
+				return fmt.Errorf("--count must be at least 1: %d", count)
+			}
+			repeat, err := cmd.Flags().GetInt("repeat")
+			if err != nil {
+				return err
+			}
+			if repeat < 1 {
+				return fmt.Errorf("--repeat must be at least 1: %d", repeat)
+			}
+			seed, err := cmd.Flags().GetInt64("seed")
+			if err != nil {
+				return err
+			}
+			distribution, err := cmd.Flags().GetString("dist")
+			if err != nil {
+				return err
+			}
+			distributions := []string{"sequential", "random", "excursion"}
+			if distribution != "" {
+				if _, ok := benchInputs[distribution]; !ok {
+					return fmt.Errorf("unexpected value for --dist: %s", distribution)
+				}
+				distributions = []string{distribution}
+			}
+			name, err := cmd.Flags().GetString("map")
+			if err != nil {
+				return err
+			}
+			benchmarks := make([]benchmark, 0)
+			for _, b := range benchmarkFunctions() {
+				if name == "" || name == b.name {
+					benchmarks = append(benchmarks, b)
+				}
+			}
+			if len(benchmarks) == 0 {
+				return fmt.Errorf("invalid value for --map: %s", name)
+			}
+
+			rng := rand.New(rand.NewSource(seed))
+			timings := make([][]benchTiming, len(distributions))
+			for i, d := range distributions {
+				inputs, skipped := benchInputs[d](from, to, count, rng)
+				if skipped > 0 {
+					log.Printf("skipped %d %s n whose orbits leave the range of uint64", skipped, d)
+				}
+				if len(inputs) == 0 {
+					return fmt.Errorf("no %s n in [%d, %d) stay in the range of uint64", d, from, to)
+				}
+				log.Printf("timing %d %s n from [%d, %d), best of %d...", len(inputs), d, from, to, repeat)
+				timings[i], err = timeBenchmarks(benchmarks, inputs, repeat)
+				if err != nil {
+					return err
+				}
+			}
+			printBenchmarks(distributions, benchmarks, timings)
+
+			p := newPlot()
+			p.Title.Text = fmt.Sprintf("Wall-clock Cost per Standard Step, n in [%d, %d)", from, to)
+			p.Y.Label.Text = "ns per standard step"
+			buildBenchmarkBars(p, distributions, benchmarks, timings)
+			return saveToPNG(fmt.Sprintf("bench_%d_%d.png", from, to), 1500, 900, p)
+		},
+	}
+)
+
+func init() {
+	benchCmd.Flags().Uint64("from", 1, "time n from this value")
+	benchCmd.Flags().Uint64("to", 1<<32, "time n up to but not including this value")
+	benchCmd.Flags().Int("count", 1<<16, "number of n timed for each distribution")
+	benchCmd.Flags().String("dist", "", "distribution of n: sequential (from --from) | random (uniform) | excursion (random n with the highest max/n). leave blank for all")
+	benchCmd.Flags().String("map", "", "name of the function or map to time: f, g, h or a registered acceleration of C. leave blank for all")
+	benchCmd.Flags().Int("repeat", 5, "number of times each is timed, keeping the fastest")
+	benchCmd.Flags().Int64("seed", 1, "seed for the random n")
+}
+
+// benchmark is a stopping time function or map timed by the bench command. run returns the standard total stopping
+// time of n, which is the same for every benchmark and is used to check them against each other.
+type benchmark struct {
+	name string
+	run  func(n uint64) uint64
+}
+
+// benchmarkFunctions returns f, g, h and each of shared.Maps. The inputs are chosen so that no orbit leaves the range
+// of uint64, so the functions are called without the overflow check of Functions.
+func benchmarkFunctions() []benchmark {
+	benchmarks := []benchmark{
+		{name: "f", run: func(n uint64) uint64 { return shared.CollatzStoppingTimeF(n).StandardTime }},
+		{name: "g", run: func(n uint64) uint64 { return shared.CollatzStoppingTimeG(n).StandardTime }},
+		{name: "h", run: func(n uint64) uint64 { return shared.CollatzStoppingTimeH(n).StandardTime }},
+	}
+	for _, m := range shared.Maps {
+		m := m
+		benchmarks = append(benchmarks, benchmark{name: m.Name, run: func(n uint64) uint64 {
+			_, normalTime, _ := m.StoppingTime(n)
+			return normalTime
+		}})
+	}
+	return benchmarks
+}
+
+// benchInputs return count n from [from, to) for each distribution, leaving out the n whose orbit leaves the range of
+// uint64, along with the number left out
+var benchInputs = map[string]func(from, to uint64, count int, rng *rand.Rand) ([]uint64, int){
+	"sequential": func(from, to uint64, count int, rng *rand.Rand) ([]uint64, int) {
+		inputs := make([]uint64, 0, count)
+		skipped := 0
+		for n := from; n < to && len(inputs)+skipped < count; n++ {
+			if shared.CollatzStoppingTimeF(n).Overflow {
+				skipped++
+				continue
+			}
+			inputs = append(inputs, n)
+		}
+		return inputs, skipped
+	},
+	"random": func(from, to uint64, count int, rng *rand.Rand) ([]uint64, int) {
+		inputs := make([]uint64, 0, count)
+		skipped := 0
+		for len(inputs)+skipped < count {
+			n := uniform(from, to, rng)
+			if shared.CollatzStoppingTimeF(n).Overflow {
+				skipped++
+				continue
+			}
+			inputs = append(inputs, n)
+		}
+		return inputs, skipped
+	},
+	// the orbits that climb furthest above n are the longest and least regular for their size
+	"excursion": func(from, to uint64, count int, rng *rand.Rand) ([]uint64, int) {
+		type candidate struct {
+			n         uint64
+			excursion float64
+		}
+		candidates := make([]candidate, 0, benchCandidates*count)
+		skipped := 0
+		for i := 0; i < benchCandidates*count; i++ {
+			n := uniform(from, to, rng)
+			r := shared.CollatzStoppingTimeF(n)
+			if r.Overflow {
+				skipped++
+				continue
+			}
+			candidates = append(candidates, candidate{n: n, excursion: float64(r.Max) / float64(n)})
+		}
+		sort.Slice(candidates, func(i, j int) bool {
+			return candidates[i].excursion > candidates[j].excursion
+		})
+		if len(candidates) > count {
+			candidates = candidates[:count]
+		}
+		inputs := make([]uint64, len(candidates))
+		for i, c := range candidates {
+			inputs[i] = c.n
+		}
+		return inputs, skipped
+	},
+}
+
+// benchCandidates is the number of random n drawn for each n kept by the excursion distribution
+const benchCandidates = 16
+
+// uniform returns a uniform random n in [from, to)
+func uniform(from, to uint64, rng *rand.Rand) uint64 {
+	span := to - from
+	if span <= math.MaxInt64 {
+		return from + uint64(rng.Int63n(int64(span)))
+	}
+	for {
+		if n := rng.Uint64(); n >= from && n < to {
+			return n
+		}
+	}
+}
+
+// benchTiming is the fastest time taken by a benchmark over a set of inputs, along with the standard steps they cover
+type benchTiming struct {
+	elapsed       time.Duration
+	inputs        int
+	standardSteps uint64
+}
+
+func (t benchTiming) perN() float64 {
+	return float64(t.elapsed.Nanoseconds()) / float64(t.inputs)
+}
+
+func (t benchTiming) perStandardStep() float64 {
+	return float64(t.elapsed.Nanoseconds()) / float64(t.standardSteps)
+}
+
+// timeBenchmarks times each benchmark over the inputs on a single goroutine, keeping the fastest of repeat runs, and
+// checks that they all cover the same number of standard steps
+func timeBenchmarks(benchmarks []benchmark, inputs []uint64, repeat int) ([]benchTiming, error) {
+	timings := make([]benchTiming, len(benchmarks))
+	for i, b := range benchmarks {
+		// the first call builds any lookup tables the map needs
+		b.run(inputs[0])
+		timings[i] = benchTiming{elapsed: time.Duration(math.MaxInt64), inputs: len(inputs)}
+		for r := 0; r < repeat; r++ {
+			start := time.Now()
+			steps := uint64(0)
+			for _, n := range inputs {
+				steps += b.run(n)
+			}
+			elapsed := time.Since(start)
+			if elapsed < timings[i].elapsed {
+				timings[i].elapsed = elapsed
+			}
+			timings[i].standardSteps = steps
+		}
+		if timings[i].standardSteps != timings[0].standardSteps {
+			return nil, fmt.Errorf("%s covers %d standard steps but %s covers %d", b.name, timings[i].standardSteps, benchmarks[0].name, timings[0].standardSteps)
+		}
+	}
+	return timings, nil
+}
+
+// printBenchmarks prints the cost of each benchmark per n and per standard step, and its speedup over the first
+func printBenchmarks(distributions []string, benchmarks []benchmark, timings [][]benchTiming) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "distribution\tfn\tn\tstandard steps per n\tns per n\tns per standard step\tspeedup over %s\t\n", benchmarks[0].name)
+	for i, d := range distributions {
+		for j, b := range benchmarks {
+			t := timings[i][j]
+			fmt.Fprintf(w, "%s\t%s\t%d\t%.2f\t%.1f\t%.3f\t%.2f\t\n", d, b.name, t.inputs, float64(t.standardSteps)/float64(t.inputs),
+				t.perN(), t.perStandardStep(), float64(timings[i][0].elapsed)/float64(t.elapsed))
+		}
+	}
+	w.Flush()
+}
+
+// benchColors are the colors of the bars of each distribution
+var benchColors = []color.NRGBA{
+	{R: 255, G: 0, B: 0, A: 160},
+	{R: 0, G: 160, B: 0, A: 160},
+	{R: 0, G: 0, B: 255, A: 160},
+}
+
+func buildBenchmarkBars(p *plot.Plot, distributions []string, benchmarks []benchmark, timings [][]benchTiming) {
+	width := vg.Points(20)
+	names := make([]string, len(benchmarks))
+	for j, b := range benchmarks {
+		names[j] = b.name
+	}
+	for i, d := range distributions {
+		values := make(plotter.Values, len(benchmarks))
+		for j := range benchmarks {
+			values[j] = timings[i][j].perStandardStep()
+		}
+		bars, err := plotter.NewBarChart(values, width)
+		if err != nil {
+			panic(err)
+		}
+		bars.LineStyle.Width = 0
+		bars.Color = benchColors[i%len(benchColors)]
+		bars.Offset = width * vg.Length(2*i-len(distributions)+1) / 2
+		p.Add(bars)
+		p.Legend.Add(d, bars)
+	}
+	p.NominalX(names...)
+	p.X.Min, p.X.Max = -0.5, float64(len(benchmarks))-0.5
+	// leave room for the legend above the bars
+	p.Y.Min = 0
+	p.Y.Max *= 1.2
+}
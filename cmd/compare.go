@@ -4,8 +4,13 @@ import (
 	"fmt"
 	"log"
 	"math"
+	"math/big"
+	"math/bits"
+	"math/rand"
 	"runtime"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/theriault/collatz/shared"
@@ -14,7 +19,7 @@ import (
 var (
 	compareCmd = &cobra.Command{
 		Use:   "compare",
-		Short: "Compare g (reduced) or h (main result) to f to empirically verify equality",
+		Short: "Compare g (reduced) or h (main result) to f, h to A160541 or exact rational arithmetic, or a jump table, batch or lock-step evaluation to f, g and h, to empirically verify equality",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if fn == "" {
 				return fmt.Errorf("--fn is required")
@@ -23,13 +28,53 @@ var (
 				return fmt.Errorf("--k must be in the range 1..20: %d", power)
 			}
 			limit := uint64(math.Pow10(power))
-			if fn == "g" {
+			switch fn {
+			case "g":
 				log.Printf("comparing g(x) to f(x) from 1..10^%d", power)
-				compare(limit, shared.CollatzStoppingTimeG, shared.CollatzStoppingTimeF)
-			}
-			if fn == "h" {
+				compare(limit, Functions["g"], Functions["f"])
+			case "h":
 				log.Printf("comparing h(x) to f(x) from 1..10^%d", power)
-				compare(limit, shared.CollatzStoppingTimeH, shared.CollatzStoppingTimeF)
+				compare(limit, Functions["h"], Functions["f"])
+			case "a160541":
+				log.Printf("comparing h(x) to A160541 from 1..10^%d", power)
+				compareA160541(limit)
+			case "jump":
+				bits, err := cmd.Flags().GetUint("bits")
+				if err != nil {
+					return err
+				}
+				if bits < 1 || bits > 20 {
+					return fmt.Errorf("--bits must be in the range 1..20: %d", bits)
+				}
+				log.Printf("comparing %d-bit jump table to f(x), g(x), h(x) from 1..10^%d", bits, power)
+				return compareJumpTable(limit, shared.NewJumpTable(bits))
+			case "batch":
+				log.Printf("comparing batch evaluation of f(x), g(x), h(x) to the functions from 1..10^%d", power)
+				return compareBatch(limit)
+			case "lanes":
+				samples, err := cmd.Flags().GetUint64("samples")
+				if err != nil {
+					return err
+				}
+				seed, err := cmd.Flags().GetInt64("seed")
+				if err != nil {
+					return err
+				}
+				log.Printf("comparing lock-step evaluation of f(x), g(x), h(x) to the functions from 1..10^%d, %d random inputs", power, samples)
+				return compareLanes(limit, samples, seed)
+			case "rational":
+				samples, err := cmd.Flags().GetUint64("samples")
+				if err != nil {
+					return err
+				}
+				seed, err := cmd.Flags().GetInt64("seed")
+				if err != nil {
+					return err
+				}
+				log.Printf("comparing R′ iterates of h(x) to exact rational arithmetic from 1..10^%d, %d random and adversarial inputs", power, samples)
+				return compareRational(limit, samples, seed)
+			default:
+				return fmt.Errorf("unexpected value for --fn: %s", fn)
 			}
 			return nil
 		},
@@ -37,11 +82,15 @@ var (
 )
 
 func init() {
-	compareCmd.Flags().StringVar(&fn, "fn", "", "which function to plot: g, h")
+	compareCmd.Flags().StringVar(&fn, "fn", "", "which function to compare: g, h, a160541, rational, jump, batch, lanes")
+	compareCmd.Flags().Uint("bits", 16, "number of bits processed per jump with --fn jump")
+	compareCmd.Flags().Uint64("samples", 100_000, "number of random inputs to check with --fn rational or lanes")
+	compareCmd.Flags().Int64("seed", 1, "seed for the random inputs of --fn rational or lanes")
 	compareCmd.Flags().IntVar(&power, "k", 5, "examine n up to 10^k")
 }
 
-func compare(limit uint64, fnA func(n uint64) (uint64, uint64, uint64), fnB func(n uint64) (uint64, uint64, uint64)) {
+// compare reports the first x where the standard total stopping time of fnA differs from that of fnB
+func compare(limit uint64, fnA func(n uint64) shared.Result, fnB func(n uint64) shared.Result) {
 	var wg sync.WaitGroup
 	workers := uint64(runtime.GOMAXPROCS(0))
 	A := make([]uint64, limit)
@@ -51,10 +100,8 @@ func compare(limit uint64, fnA func(n uint64) (uint64, uint64, uint64), fnB func
 		go (func(worker uint64, workerCount uint64, limit uint64) {
 			defer wg.Done()
 			for i := worker; i < limit; i += workerCount {
-				_, a, _ := fnA(i)
-				b, _, _ := fnB(i)
-				A[i] = a
-				B[i] = b
+				A[i] = fnA(i).StandardTime
+				B[i] = fnB(i).StandardTime
 			}
 		})(w, workers, limit)
 	}
@@ -66,3 +113,284 @@ func compare(limit uint64, fnA func(n uint64) (uint64, uint64, uint64), fnB func
 		}
 	}
 }
+
+// maxCounterexamples is the number of counterexamples reported by compareA160541
+const maxCounterexamples = 10
+
+func compareA160541(limit uint64) {
+	h := Functions["h"]
+	var wg sync.WaitGroup
+	workers := uint64(runtime.GOMAXPROCS(0))
+	completed := make(chan []uint64, workers)
+	for w := uint64(1); w <= workers; w++ {
+		wg.Add(1)
+		go (func(worker uint64, workerCount uint64, limit uint64, completed chan<- []uint64) {
+			defer wg.Done()
+			counterexamples := make([]uint64, 0)
+			for i := worker; i < limit && len(counterexamples) < maxCounterexamples; i += workerCount {
+				if h(i).Time != shared.A160541(i) {
+					counterexamples = append(counterexamples, i)
+				}
+			}
+			completed <- counterexamples
+		})(w, workers, limit, completed)
+	}
+	wg.Wait()
+	close(completed)
+
+	counterexamples := make([]uint64, 0)
+	for v := range completed {
+		counterexamples = append(counterexamples, v...)
+	}
+	if len(counterexamples) == 0 {
+		log.Printf("h(x) = A160541(x) for all x < %d", limit)
+		return
+	}
+	sort.Slice(counterexamples, func(i, j int) bool { return counterexamples[i] < counterexamples[j] })
+	if len(counterexamples) > maxCounterexamples {
+		counterexamples = counterexamples[:maxCounterexamples]
+	}
+	for _, n := range counterexamples {
+		fmt.Printf("%d: h = %d != A160541 = %d\n", n, h(n).Time, shared.A160541(n))
+		fmt.Printf("  trajectory: %v\n", shared.Trajectory(n))
+	}
+}
+
+// checkRPrime follows the trajectory of n under R′ using both shared.RPrime and shared.RPrimeRat, and checks that
+// shared.CollatzStoppingTimeH, which takes its steps with shared.RPrime, agrees with the trajectory. It returns the
+// number of iterates compared, whether the trajectory leaves the range of uint64, and the first x where they disagree,
+// or 0.
+func checkRPrime(n uint64) (uint64, bool, uint64) {
+	h := shared.CollatzStoppingTimeH(n)
+	x := n
+	time, normalTime := uint64(0), uint64(0)
+	if x&1 == 0 {
+		m := uint64(bits.TrailingZeros64(x))
+		x >>= m // x/2^m
+		time, normalTime = 1, m
+	}
+	iterates := uint64(0)
+	for x != 1 {
+		expected, v, m := shared.RPrimeRat(new(big.Int).SetUint64(x))
+		actual, actualV, actualM, ok := shared.RPrime(x)
+		// the numerator (3/2)^ν2(x+1) (x+1) - 1 is the largest value reached during the step
+		if expected.BitLen()+int(m) > 64 {
+			if ok || !h.Overflow {
+				return iterates, true, x
+			}
+			return iterates, true, 0
+		}
+		if !ok || actual != expected.Uint64() || actualV != uint64(v) || actualM != uint64(m) {
+			return iterates, false, x
+		}
+		x = actual
+		time++
+		normalTime += uint64(2*v + m)
+		iterates++
+	}
+	if h.Overflow || h.Time != time || h.StandardTime != normalTime {
+		return iterates, false, n
+	}
+	return iterates, false, 0
+}
+
+// rationalInputs returns random odd inputs of every bit length along with inputs chosen to stress the bit trick: long
+// runs of trailing ones, values next to powers of 2 and 3, and values near the point where the step overflows.
+func rationalInputs(samples uint64, seed int64) []uint64 {
+	rng := rand.New(rand.NewSource(seed))
+	inputs := make([]uint64, 0, samples+64*8)
+	for i := uint64(0); i < samples; i++ {
+		bits := 2 + rng.Intn(63)
+		inputs = append(inputs, (rng.Uint64()>>(64-bits))|1)
+	}
+	for j := 2; j <= 64; j++ {
+		ones := uint64(math.MaxUint64) >> (64 - j)
+		inputs = append(inputs, ones, ones-2, ones>>1|1<<(j-1))
+		power3 := uint64(1)
+		for power3 <= ones/3 {
+			power3 *= 3
+		}
+		inputs = append(inputs, power3, power3-2, power3+2)
+	}
+	overflow := uint64(math.MaxUint64) / 3 * 2
+	for d := uint64(0); d < 64; d++ {
+		inputs = append(inputs, (overflow-d)|1, (overflow+d)|1)
+	}
+	return inputs
+}
+
+func compareRational(limit uint64, samples uint64, seed int64) error {
+	inputs := rationalInputs(samples, seed)
+	var wg sync.WaitGroup
+	workers := uint64(runtime.GOMAXPROCS(0))
+	type result struct {
+		iterates   uint64
+		overflows  uint64
+		mismatches []uint64
+	}
+	results := make([]result, workers)
+	for w := uint64(0); w < workers; w++ {
+		wg.Add(1)
+		go (func(worker uint64, workerCount uint64) {
+			defer wg.Done()
+			check := func(n uint64) {
+				iterates, overflow, mismatch := checkRPrime(n)
+				results[worker].iterates += iterates
+				if overflow {
+					results[worker].overflows++
+				}
+				if mismatch != 0 {
+					results[worker].mismatches = append(results[worker].mismatches, mismatch)
+				}
+			}
+			for i := 1 + worker; i < limit; i += workerCount {
+				check(i)
+			}
+			for i := worker; i < uint64(len(inputs)); i += workerCount {
+				check(inputs[i])
+			}
+		})(w, workers)
+	}
+	wg.Wait()
+
+	total := result{}
+	for _, r := range results {
+		total.iterates += r.iterates
+		total.overflows += r.overflows
+		total.mismatches = append(total.mismatches, r.mismatches...)
+	}
+	log.Printf("compared %d iterates of %d inputs, %d trajectories left the range of uint64", total.iterates, limit-1+uint64(len(inputs)), total.overflows)
+	if len(total.mismatches) > 0 {
+		sort.Slice(total.mismatches, func(i, j int) bool { return total.mismatches[i] < total.mismatches[j] })
+		x := total.mismatches[0]
+		expected, v, m := shared.RPrimeRat(new(big.Int).SetUint64(x))
+		actual, actualV, actualM, ok := shared.RPrime(x)
+		return fmt.Errorf("%d inputs disagree, first at x = %d: RPrime = %d, %d, %d, %t, R′ = %s, %d, %d, h = %+v", len(total.mismatches), x, actual, actualV, actualM, ok, expected, v, m, shared.CollatzStoppingTimeH(x))
+	}
+	return nil
+}
+
+// timeEach calls fn for each n in 1..limit in parallel, and returns how long it took
+func timeEach(limit uint64, fn func(n uint64)) time.Duration {
+	start := time.Now()
+	var wg sync.WaitGroup
+	workers := uint64(runtime.GOMAXPROCS(0))
+	for w := uint64(1); w <= workers; w++ {
+		wg.Add(1)
+		go (func(worker uint64, workerCount uint64, limit uint64) {
+			defer wg.Done()
+			for i := worker; i < limit; i += workerCount {
+				fn(i)
+			}
+		})(w, workers, limit)
+	}
+	wg.Wait()
+	return time.Since(start)
+}
+
+func compareJumpTable(limit uint64, table *shared.JumpTable) error {
+	jumped := make([][3]uint64, limit)
+	overflowed := make([]bool, limit)
+	jumpedTime := timeEach(limit, func(n uint64) {
+		f, g, h, ok := table.StoppingTimes(n)
+		jumped[n] = [3]uint64{f, g, h}
+		overflowed[n] = !ok
+	})
+	log.Printf("jump table: %s", jumpedTime)
+	for n := uint64(1); n < limit; n++ {
+		if overflowed[n] {
+			return fmt.Errorf("the orbit of %d leaves the range of uint64 in the jump table", n)
+		}
+	}
+	for i, fn := range []string{"f", "g", "h"} {
+		results := make([]shared.Result, limit)
+		stoppingTime := Functions[fn]
+		elapsed := timeEach(limit, func(n uint64) {
+			results[n] = stoppingTime(n)
+		})
+		log.Printf("%s: %s", Types[fn].Title, elapsed)
+		for n := uint64(1); n < limit; n++ {
+			if results[n].Time != jumped[n][i] {
+				return fmt.Errorf("%d: %s = %d but the jump table gives %d", n, Types[fn].Title, results[n].Time, jumped[n][i])
+			}
+		}
+	}
+	log.Printf("the jump table agrees with f(x), g(x), h(x) for all x < %d", limit)
+	return nil
+}
+
+// compareBatch checks every field of the Result from batch evaluation against the stopping time functions, and
+// reports how long each took
+func compareBatch(limit uint64) error {
+	for _, fn := range []string{"f", "g", "h"} {
+		expected := make([]shared.Result, limit)
+		stoppingTime := Functions[fn]
+		elapsed := timeEach(limit, func(n uint64) {
+			expected[n] = stoppingTime(n)
+		})
+		start := time.Now()
+		evaluate := batched(fn, limit)
+		cached := time.Since(start)
+		actual := make([]shared.Result, limit)
+		batchElapsed := timeEach(limit, func(n uint64) {
+			actual[n] = evaluate(n)
+		})
+		log.Printf("%s: %s, batch: %s + %s to fill the cache", Types[fn].Title, elapsed, batchElapsed, cached)
+		for n := uint64(1); n < limit; n++ {
+			if actual[n] != expected[n] {
+				return fmt.Errorf("%d: %s = %+v but batch evaluation gives %+v", n, Types[fn].Title, expected[n], actual[n])
+			}
+		}
+	}
+	log.Printf("batch evaluation agrees with f(x), g(x), h(x) for all x < %d", limit)
+	return nil
+}
+
+// compareLanes checks every field of the Result from lock-step evaluation with each number of lanes against the
+// stopping time functions, over 1..limit and random inputs of every magnitude, many of which overflow, and reports how
+// long each took
+func compareLanes(limit uint64, samples uint64, seed int64) error {
+	rng := rand.New(rand.NewSource(seed))
+	random := make([]uint64, samples)
+	for i := range random {
+		random[i] = rng.Uint64()>>rng.Intn(64) | 1
+	}
+	// the functions without the exit on overflow of Functions
+	functions := map[string]func(n uint64) shared.Result{
+		"f": shared.CollatzStoppingTimeF,
+		"g": shared.CollatzStoppingTimeG,
+		"h": shared.CollatzStoppingTimeH,
+	}
+	for _, fn := range []string{"f", "g", "h"} {
+		expected := make([]shared.Result, limit)
+		stoppingTime := functions[fn]
+		elapsed := timeEach(limit, func(n uint64) {
+			expected[n] = stoppingTime(n)
+		})
+		expectedRandom := make([]shared.Result, samples)
+		for i, n := range random {
+			expectedRandom[i] = stoppingTime(n)
+		}
+		for width := shared.MinLanes; width <= shared.MaxLanes; width++ {
+			lanes := Lanes[fn](width)
+			actual := make([]shared.Result, limit)
+			start := time.Now()
+			lanes.EvaluateRange(1, actual[1:])
+			log.Printf("%s: %s, %d lanes: %s", Types[fn].Title, elapsed, width, time.Since(start))
+			for n := uint64(1); n < limit; n++ {
+				if actual[n] != expected[n] {
+					return fmt.Errorf("%d: %s = %+v but %d lanes give %+v", n, Types[fn].Title, expected[n], width, actual[n])
+				}
+			}
+			actual = make([]shared.Result, samples)
+			lanes.Evaluate(random, actual)
+			for i, n := range random {
+				if actual[i] != expectedRandom[i] {
+					return fmt.Errorf("%d: %s = %+v but %d lanes give %+v", n, Types[fn].Title, expectedRandom[i], width, actual[i])
+				}
+			}
+		}
+	}
+	log.Printf("lock-step evaluation agrees with f(x), g(x), h(x) for all x < %d and %d random x", limit, samples)
+	return nil
+}
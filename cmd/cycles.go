@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"runtime"
+	"sort"
+	"sync"
+
+	"github.com/spf13/cobra"
+	"github.com/theriault/collatz/shared"
+)
+
+var (
+	cyclesCmd = &cobra.Command{
+		Use:   "cycles",
+		Short: "Search for cycles of C, R, R′ or a generalized qx+r map using Brent's algorithm",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if power < 1 || power > 20 {
+				return fmt.Errorf("--k must be in the range 1..20: %d", power)
+			}
+			limit := uint64(math.Pow10(power))
+			q, err := cmd.Flags().GetUint64("q")
+			if err != nil {
+				return err
+			}
+			r, err := cmd.Flags().GetUint64("r")
+			if err != nil {
+				return err
+			}
+			maxSteps, err := cmd.Flags().GetUint64("max-steps")
+			if err != nil {
+				return err
+			}
+
+			var step shared.StepFunc
+			var title string
+			generalized := cmd.Flags().Changed("q") || cmd.Flags().Changed("r")
+			if generalized {
+				if q&1 == 0 || r&1 == 0 {
+					return fmt.Errorf("--q and --r must be odd: %d, %d", q, r)
+				}
+				step = shared.GeneralizedStep(q, r)
+				title = fmt.Sprintf("%dx+%d", q, r)
+			} else if fn == "" || fn == "f" {
+				step = shared.GeneralizedStep(3, 1)
+				title = "C"
+			} else if fn == "g" {
+				step = shared.MapStep(shared.MapR)
+				title = "R"
+			} else if fn == "h" {
+				step = shared.MapStep(shared.MapRPrime)
+				title = "R′"
+			} else {
+				return fmt.Errorf("invalid value for --fn: %s", fn)
+			}
+
+			log.Printf("searching for cycles of %s from 1..10^%d...", title, power)
+			cycles, unresolved := findCycles(limit, step, maxSteps)
+			for _, cycle := range cycles {
+				fmt.Printf("cycle min=%d length=%d max=%d\n", cycle.Min, cycle.Length, cycle.Max)
+			}
+			if len(unresolved) > 0 {
+				fmt.Printf("%d trajectories overflowed or took more than %d steps before dropping below their start, the first was %d\n", len(unresolved), maxSteps, unresolved[0])
+			}
+			if !generalized {
+				for _, cycle := range cycles {
+					if cycle.Min != 1 {
+						return fmt.Errorf("found a non-trivial cycle of %s with min=%d", title, cycle.Min)
+					}
+				}
+				if len(unresolved) == 0 {
+					log.Printf("no cycles of %s other than the one through 1 have an element below 10^%d", title, power)
+				}
+			}
+			return nil
+		},
+	}
+)
+
+func init() {
+	cyclesCmd.Flags().StringVar(&fn, "fn", "", "which map to search: f (C), g (R), h (R′). leave blank for C")
+	cyclesCmd.Flags().IntVar(&power, "k", 5, "examine n up to 10^k")
+	cyclesCmd.Flags().Uint64("q", 3, "search the generalized map qx+r instead")
+	cyclesCmd.Flags().Uint64("r", 1, "search the generalized map qx+r instead")
+	cyclesCmd.Flags().Uint64("max-steps", 100_000, "give up on a trajectory after this many steps")
+}
+
+// findCycles returns the distinct cycles entered by trajectories starting below limit, ordered by their smallest
+// element, along with the starting values whose trajectories could not be followed until they dropped below their
+// start or entered a cycle.
+func findCycles(limit uint64, step shared.StepFunc, maxSteps uint64) ([]shared.Cycle, []uint64) {
+	var wg sync.WaitGroup
+	workers := uint64(runtime.GOMAXPROCS(0))
+	found := make([]map[uint64]shared.Cycle, workers)
+	unresolved := make([][]uint64, workers)
+	for w := uint64(0); w < workers; w++ {
+		wg.Add(1)
+		go (func(worker uint64, workerCount uint64, limit uint64) {
+			defer wg.Done()
+			found[worker] = make(map[uint64]shared.Cycle)
+			for i := 1 + worker; i < limit; i += workerCount {
+				cycle, stop := shared.FindCycle(i, step, maxSteps)
+				switch stop {
+				case shared.StopCycle:
+					found[worker][cycle.Min] = cycle
+				case shared.StopOverflow, shared.StopMaxSteps:
+					unresolved[worker] = append(unresolved[worker], i)
+				}
+			}
+		})(w, workers, limit)
+	}
+	wg.Wait()
+
+	distinct := make(map[uint64]shared.Cycle)
+	all := make([]uint64, 0)
+	for w := range found {
+		for min, cycle := range found[w] {
+			distinct[min] = cycle
+		}
+		all = append(all, unresolved[w]...)
+	}
+	cycles := make([]shared.Cycle, 0, len(distinct))
+	for _, cycle := range distinct {
+		cycles = append(cycles, cycle)
+	}
+	sort.Slice(cycles, func(i, j int) bool { return cycles[i].Min < cycles[j].Min })
+	sort.Slice(all, func(i, j int) bool { return all[i] < all[j] })
+	return cycles, all
+}
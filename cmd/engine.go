@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/spf13/cobra"
+	"github.com/theriault/collatz/shared"
+)
+
+// chunk is the number of consecutive n a worker evaluates at a time with a rangeFunction
+const chunk = 1 << 12
+
+// chunkSize returns the number of n in the chunk starting at from, stopping before limit
+func chunkSize(from uint64, limit uint64) uint64 {
+	if limit-from < chunk {
+		return limit - from
+	}
+	return chunk
+}
+
+// rangeFunction stores the Result of each n in [from, from+len(results)) in results
+type rangeFunction func(from uint64, results []shared.Result)
+
+// engine returns the stopping time function of the given type evaluated over ranges of n up to limit. Like Functions,
+// the function exits if an orbit leaves the range of uint64.
+type engine func(name string, limit uint64) rangeFunction
+
+// Lanes create a shared.Lanes for each of the Types, except the combined type
+var Lanes = map[string]func(width int) *shared.Lanes{
+	"f": shared.NewLanesF,
+	"g": shared.NewLanesG,
+	"h": shared.NewLanesH,
+}
+
+// addEngineFlags adds the flags for choosing how the stopping time functions are evaluated
+func addEngineFlags(cmd *cobra.Command) {
+	cmd.Flags().String("engine", "batch", "how to evaluate the functions: batch (cached, see --cache) | lanes (several orbits in lock-step, see --lanes)")
+	cmd.Flags().Int("lanes", 8, fmt.Sprintf("number of orbits followed in lock-step with --engine lanes, %d..%d", shared.MinLanes, shared.MaxLanes))
+}
+
+// engineFlags returns the engine given by the engine flags
+func engineFlags(cmd *cobra.Command) (engine, error) {
+	name, err := cmd.Flags().GetString("engine")
+	if err != nil {
+		return nil, err
+	}
+	width, err := cmd.Flags().GetInt("lanes")
+	if err != nil {
+		return nil, err
+	}
+	switch name {
+	case "batch":
+		return batchedRange, nil
+	case "lanes":
+		if width < shared.MinLanes || width > shared.MaxLanes {
+			return nil, fmt.Errorf("--lanes must be in the range %d..%d: %d", shared.MinLanes, shared.MaxLanes, width)
+		}
+		return func(name string, limit uint64) rangeFunction {
+			return lanesRange(name, width)
+		}, nil
+	}
+	return nil, fmt.Errorf("unexpected value for --engine: %s", name)
+}
+
+// batchedRange evaluates the function from batched for each n of a range, which also reuses the results of the range
+// that are already known. Like Functions, the function exits if an orbit leaves the range of uint64.
+func batchedRange(name string, limit uint64) rangeFunction {
+	if cacheSize == 0 {
+		fn := Functions[name]
+		return func(from uint64, results []shared.Result) {
+			for i := range results {
+				results[i] = fn(from + uint64(i))
+			}
+		}
+	}
+	b := newBatch(name, limit)
+	return func(from uint64, results []shared.Result) {
+		b.EvaluateRange(from, results)
+		for i, r := range results {
+			exitOnOverflow(from+uint64(i), r)
+		}
+	}
+}
+
+// lanesRange evaluates a range of n with a shared.Lanes following width orbits at a time
+func lanesRange(name string, width int) rangeFunction {
+	log.Printf("following %d orbits of %s at a time...", width, Types[name].Title)
+	lanes := Lanes[name](width)
+	return func(from uint64, results []shared.Result) {
+		lanes.EvaluateRange(from, results)
+		for i, r := range results {
+			exitOnOverflow(from+uint64(i), r)
+		}
+	}
+}
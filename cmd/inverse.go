@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"runtime"
+	"sync"
+
+	"github.com/spf13/cobra"
+	"github.com/theriault/collatz/shared"
+)
+
+var (
+	inverseCmd = &cobra.Command{
+		Use:   "inverse",
+		Short: "Build the inverse tree of C, R or R′ from 1 and count the numbers at each level",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			inverse, ok := InverseMaps[fn]
+			if !ok {
+				return fmt.Errorf("--fn should be f, g or h")
+			}
+			info := Types[fn]
+			depth, err := cmd.Flags().GetInt("depth")
+			if err != nil {
+				return err
+			}
+			if depth < 0 {
+				return fmt.Errorf("--depth must not be negative: %d", depth)
+			}
+			bound, err := cmd.Flags().GetUint64("bound")
+			if err != nil {
+				return err
+			}
+			if bound < 1 {
+				return fmt.Errorf("--bound must be at least 1: %d", bound)
+			}
+			check, err := cmd.Flags().GetBool("check")
+			if err != nil {
+				return err
+			}
+
+			log.Printf("building inverse tree of %s to depth %d with values up to %d...", inverse.Name, depth, bound)
+			levels := shared.InverseTree(inverse, depth, bound)
+			var forward []uint64
+			if check {
+				log.Printf("counting %s from 1..%d for comparison...", info.Title, bound)
+				forward = countLevels(bound, depth, Functions[fn])
+			}
+			total := uint64(0)
+			for t, level := range levels {
+				total += uint64(len(level))
+				if check {
+					fmt.Printf("%d %d %d\n", t, len(level), forward[t])
+				} else {
+					fmt.Printf("%d %d\n", t, len(level))
+				}
+			}
+			log.Printf("%d numbers reach 1 within %d steps of %s without exceeding %d", total, depth, inverse.Name, bound)
+			if check {
+				for t, level := range levels {
+					if uint64(len(level)) != forward[t] {
+						return fmt.Errorf("level %d has %d numbers but %d x have %s = %d", t, len(level), forward[t], info.Title, t)
+					}
+				}
+				log.Printf("every level matches %s", info.Title)
+			}
+			return nil
+		},
+	}
+)
+
+func init() {
+	inverseCmd.Flags().StringVar(&fn, "fn", "", "which function's map to invert: f (C), g (R), h (R′)")
+	inverseCmd.Flags().Int("depth", 20, "number of levels to build")
+	inverseCmd.Flags().Uint64("bound", 1_000_000, "largest value allowed in the tree")
+	inverseCmd.Flags().Bool("check", false, "compare the level counts to a forward scan of the stopping times up to the bound")
+}
+
+// InverseMaps are the inverses of the maps stepped by each of the Functions
+var InverseMaps = map[string]shared.InverseMap{
+	"f": shared.InverseC,
+	"g": shared.InverseR,
+	"h": shared.InverseRPrime,
+}
+
+// countLevels counts the x ≤ bound with each stopping time up to depth whose trajectory does not exceed the bound,
+// which are exactly the numbers in the corresponding level of the inverse tree
+func countLevels(bound uint64, depth int, fn func(n uint64) shared.Result) []uint64 {
+	var wg sync.WaitGroup
+	workers := uint64(runtime.GOMAXPROCS(0))
+	counts := make([][]uint64, workers)
+	for w := uint64(0); w < workers; w++ {
+		wg.Add(1)
+		go (func(worker uint64, workerCount uint64) {
+			defer wg.Done()
+			counts[worker] = make([]uint64, depth+1)
+			for i := 1 + worker; i <= bound; i += workerCount {
+				r := fn(i)
+				if r.Time <= uint64(depth) && r.Max <= bound {
+					counts[worker][r.Time]++
+				}
+			}
+		})(w, workers)
+	}
+	wg.Wait()
+	total := make([]uint64, depth+1)
+	for w := range counts {
+		for t, c := range counts[w] {
+			total[t] += c
+		}
+	}
+	return total
+}
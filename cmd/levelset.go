@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/theriault/collatz/shared"
+)
+
+var (
+	levelsetCmd = &cobra.Command{
+		Use:   "levelset",
+		Short: "List every n ≤ N with f(n), g(n) or h(n) equal to t by walking the inverse map",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			inverse, ok := InverseMaps[fn]
+			if !ok {
+				return fmt.Errorf("--fn should be f, g or h")
+			}
+			info := Types[fn]
+			t, err := cmd.Flags().GetInt("t")
+			if err != nil {
+				return err
+			}
+			if t < 0 {
+				return fmt.Errorf("--t must not be negative: %d", t)
+			}
+			bound, err := cmd.Flags().GetUint64("n")
+			if err != nil {
+				return err
+			}
+			if bound == math.MaxUint64 {
+				return fmt.Errorf("--n must be below 2^64 - 1: %d", bound)
+			}
+			maxValue, err := cmd.Flags().GetUint64("max-value")
+			if err != nil {
+				return err
+			}
+			count, err := cmd.Flags().GetBool("count")
+			if err != nil {
+				return err
+			}
+			check, err := cmd.Flags().GetBool("check")
+			if err != nil {
+				return err
+			}
+			if maxValue == 0 {
+				maxValue = pathRecordBound(fn, bound)
+			}
+
+			log.Printf("walking the inverse of %s for %s = %d with x ≤ %d...", inverse.Name, info.Title, t, bound)
+			start := time.Now()
+			// a scan evaluates every x ≤ n once, so the walk is only worth it while it finds fewer values than that
+			limit := math.MaxInt
+			if bound < math.MaxInt {
+				limit = int(bound)
+			}
+			levelSet, walked := shared.LevelSet(inverse, t, bound, maxValue, limit)
+			if !walked {
+				log.Printf("the inverse walk found more than %d values after %s, scanning %s from 1..%d instead...", limit, time.Since(start), info.Title, bound)
+				levelSet = scanLevelSet(bound, uint64(t), batched(fn, bound+1))
+			}
+			sort.Slice(levelSet, func(i, j int) bool { return levelSet[i] < levelSet[j] })
+			log.Printf("found %d x in %s", len(levelSet), time.Since(start))
+			if count {
+				fmt.Println(len(levelSet))
+			} else {
+				for _, x := range levelSet {
+					fmt.Println(x)
+				}
+			}
+			if check && !walked {
+				log.Printf("the inverse walk gave up, so there is nothing to check against the scan")
+			}
+			if check && walked {
+				log.Printf("scanning %s from 1..%d for comparison...", info.Title, bound)
+				start := time.Now()
+				scanned := scanLevelSet(bound, uint64(t), Functions[fn])
+				log.Printf("found %d x in %s", len(scanned), time.Since(start))
+				for i := 0; i < len(scanned) || i < len(levelSet); i++ {
+					if i >= len(levelSet) || (i < len(scanned) && scanned[i] < levelSet[i]) {
+						return fmt.Errorf("%s = %d for x = %d, which the inverse walk missed", info.Title, t, scanned[i])
+					}
+					if i >= len(scanned) || levelSet[i] < scanned[i] {
+						return fmt.Errorf("the inverse walk found x = %d, which does not have %s = %d", levelSet[i], info.Title, t)
+					}
+				}
+				log.Printf("the inverse walk matches the scan")
+			}
+			return nil
+		},
+	}
+)
+
+func init() {
+	levelsetCmd.Flags().StringVar(&fn, "fn", "", "which function: f, g, h")
+	levelsetCmd.Flags().Int("t", 10, "number of steps to 1")
+	levelsetCmd.Flags().Uint64("n", 1_000_000, "largest x to list")
+	levelsetCmd.Flags().Uint64("max-value", 0, "largest value reached by any trajectory of x ≤ n. use 0 to take it from the published path records where possible")
+	levelsetCmd.Flags().Bool("count", false, "print only the number of x found")
+	levelsetCmd.Flags().Bool("check", false, "compare against a scan of every x ≤ n")
+}
+
+// pathRecordBound returns the largest value the trajectory of any x ≤ n reaches under the map of the given function,
+// using the published path records of C, or math.MaxUint64 when n is beyond them
+func pathRecordBound(name string, n uint64) uint64 {
+	maxValue, ok := shared.PathRecordBound(n)
+	if !ok {
+		log.Printf("%d is beyond the published path records, so the values of the inverse walk are not bounded", n)
+		return math.MaxUint64
+	}
+	if name == "f" {
+		return maxValue
+	}
+	// R and R′ only reach x and the odd values y of the trajectory under C, where 3y+1 is also on the trajectory
+	if (maxValue-1)/3 < n {
+		return n
+	}
+	return (maxValue - 1) / 3
+}
+
+// scanLevelSet returns every x ≤ bound with fn(x) = t in increasing order
+func scanLevelSet(bound uint64, t uint64, fn func(n uint64) shared.Result) []uint64 {
+	var wg sync.WaitGroup
+	workers := uint64(runtime.GOMAXPROCS(0))
+	found := make([][]uint64, workers)
+	for w := uint64(0); w < workers; w++ {
+		wg.Add(1)
+		go (func(worker uint64, workerCount uint64) {
+			defer wg.Done()
+			for i := 1 + worker; i <= bound; i += workerCount {
+				if fn(i).Time == t {
+					found[worker] = append(found[worker], i)
+				}
+			}
+		})(w, workers)
+	}
+	wg.Wait()
+	all := make([]uint64, 0)
+	for w := range found {
+		all = append(all, found[w]...)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i] < all[j] })
+	return all
+}
@@ -27,6 +27,10 @@ var (
 				return fmt.Errorf("--k must be in the range 1..20: %d", power)
 			}
 			limit := uint64(math.Pow10(power))
+			evaluate, err := engineFlags(cmd)
+			if err != nil {
+				return err
+			}
 
 			p := newPlot()
 			p.Title.Text = fmt.Sprintf("%s Maximum Reached 10^%d", info.Title, power)
@@ -36,15 +40,15 @@ var (
 			p.X.Min = 1
 			if fn == "" || fn == "f" {
 				log.Printf("building f(x) scatter for 10^%d...", power)
-				buildMax(p, color.NRGBA{R: 255, G: 0, B: 0, A: 128}, limit, shared.CollatzStoppingTimeF, "f(x)")
+				buildMax(p, color.NRGBA{R: 255, G: 0, B: 0, A: 128}, limit, evaluate("f", limit), "f(x)")
 			}
 			if fn == "" || fn == "g" {
 				log.Printf("building g(x) scatter for 10^%d...", power)
-				buildMax(p, color.NRGBA{R: 0, G: 255, B: 0, A: 128}, limit, shared.CollatzStoppingTimeG, "g(x)")
+				buildMax(p, color.NRGBA{R: 0, G: 255, B: 0, A: 128}, limit, evaluate("g", limit), "g(x)")
 			}
 			if fn == "" || fn == "h" {
 				log.Printf("building h(x) scatter for 10^%d...", power)
-				buildMax(p, color.NRGBA{R: 0, G: 0, B: 255, A: 128}, limit, shared.CollatzStoppingTimeH, "h(x)")
+				buildMax(p, color.NRGBA{R: 0, G: 0, B: 255, A: 128}, limit, evaluate("h", limit), "h(x)")
 			}
 			applyConstraintsToPlot(p, minX, minY, maxX, maxY)
 			fileName := fmt.Sprintf("max_%s_%d.png", info.File, power)
@@ -56,27 +60,29 @@ var (
 func init() {
 	maxCmd.Flags().StringVar(&fn, "fn", "", "which function to plot: f, g, h. leave blank for all")
 	maxCmd.Flags().IntVar(&power, "k", 7, "examine n up to 10^k")
+	addEngineFlags(maxCmd)
 	maxCmd.Flags().Float64Var(&minX, "min-x", 0, "min x to show on plot. use 0 for min of data")
 	maxCmd.Flags().Float64Var(&minY, "min-y", 0, "min y to show on plot. use 0 for min of data")
 	maxCmd.Flags().Float64Var(&maxX, "max-x", 10_000, "max x to show on plot. use 0 for max of data")
 	maxCmd.Flags().Float64Var(&maxY, "max-y", 100_000, "max y to show on plot. use 0 for max of data")
 }
 
-func buildMax(p *plot.Plot, fill color.NRGBA, limit uint64, fn func(n uint64) (uint64, uint64, uint64), title string) {
+func buildMax(p *plot.Plot, fill color.NRGBA, limit uint64, evaluate rangeFunction, title string) {
 	var wg sync.WaitGroup
 	workers := uint64(runtime.GOMAXPROCS(0))
 	completed := make(chan plotter.XYs, workers)
-	for w := uint64(1); w <= workers; w++ {
+	for w := uint64(0); w < workers; w++ {
 		wg.Add(1)
 		go (func(worker uint64, workerCount uint64, limit uint64, completed chan<- plotter.XYs) {
 			defer wg.Done()
-			xys := make(plotter.XYs, limit/workerCount+1)
-			j := 0
-			for i := worker; i < limit; i += workerCount {
-				_, _, a := fn(i)
-				xys[j].X = float64(i)
-				xys[j].Y = float64(a)
-				j++
+			xys := make(plotter.XYs, 0, limit/workerCount+chunk)
+			results := make([]shared.Result, chunk)
+			for from := 1 + worker*chunk; from < limit; from += workerCount * chunk {
+				batch := results[:chunkSize(from, limit)]
+				evaluate(from, batch)
+				for j, r := range batch {
+					xys = append(xys, plotter.XY{X: float64(from + uint64(j)), Y: float64(r.Max)})
+				}
 			}
 			completed <- xys
 		})(w, workers, limit, completed)
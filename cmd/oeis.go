@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+	"github.com/theriault/collatz/shared"
+)
+
+var (
+	oeisCmd = &cobra.Command{
+		Use:   "oeis",
+		Short: "Verify or export the OEIS sequences given by f, g and h using b-files",
+	}
+
+	oeisVerifyCmd = &cobra.Command{
+		Use:   "verify",
+		Short: "Compare the terms of a b-file to f (A006577), g (A286380) or h (A160541) and report the first discrepancy",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			stoppingTime, ok := Functions[fn]
+			if !ok {
+				return fmt.Errorf("--fn should be f, g or h")
+			}
+			info := Types[fn]
+			fileName, err := cmd.Flags().GetString("file")
+			if err != nil {
+				return err
+			}
+			if fileName == "" {
+				return fmt.Errorf("--file is required")
+			}
+			f, err := os.Open(fileName)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			terms, err := shared.ReadBFile(f)
+			if err != nil {
+				return fmt.Errorf("%s: %w", fileName, err)
+			}
+			log.Printf("comparing %d terms of %s to %s (%s)", len(terms), fileName, info.Title, info.OEIS)
+			if i, value, ok := verifyTerms(terms, stoppingTime); !ok {
+				return fmt.Errorf("a(%d) = %d but %s = %d", terms[i].N, terms[i].Value, strings.Replace(info.Title, "x", fmt.Sprint(terms[i].N), 1), value)
+			}
+			log.Printf("all %d terms match", len(terms))
+			return nil
+		},
+	}
+
+	oeisExportCmd = &cobra.Command{
+		Use:   "export",
+		Short: "Write the values of f, g or h over a range as a b-file",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			stoppingTime, ok := Functions[fn]
+			if !ok {
+				return fmt.Errorf("--fn should be f, g or h")
+			}
+			info := Types[fn]
+			from, err := cmd.Flags().GetUint64("from")
+			if err != nil {
+				return err
+			}
+			to, err := cmd.Flags().GetUint64("to")
+			if err != nil {
+				return err
+			}
+			if from < 1 || to <= from {
+				return fmt.Errorf("expected 1 <= --from < --to: %d, %d", from, to)
+			}
+			fileName, err := cmd.Flags().GetString("file")
+			if err != nil {
+				return err
+			}
+			if fileName == "" {
+				fileName = "results/b" + strings.TrimPrefix(info.OEIS, "A") + ".txt"
+			}
+
+			terms := make([]shared.Term, to-from)
+			var wg sync.WaitGroup
+			workers := uint64(runtime.GOMAXPROCS(0))
+			for w := uint64(0); w < workers; w++ {
+				wg.Add(1)
+				go (func(worker uint64, workerCount uint64) {
+					defer wg.Done()
+					for i := worker; i < to-from; i += workerCount {
+						a := stoppingTime(from + i).Time
+						terms[i] = shared.Term{N: from + i, Value: a}
+					}
+				})(w, workers)
+			}
+			wg.Wait()
+
+			log.Printf("writing %s for n = %d..%d to %s...", info.OEIS, from, to-1, fileName)
+			f, err := os.Create(fileName)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			header := []string{
+				fmt.Sprintf("%s: %s for n = %d..%d", info.OEIS, info.Title, from, to-1),
+				"generated by collatz oeis export",
+			}
+			return shared.WriteBFile(f, header, terms)
+		},
+	}
+)
+
+func init() {
+	oeisVerifyCmd.Flags().StringVar(&fn, "fn", "", "which function to verify: f, g, h")
+	oeisVerifyCmd.Flags().String("file", "", "path to the b-file to verify against")
+	oeisExportCmd.Flags().StringVar(&fn, "fn", "", "which function to export: f, g, h")
+	oeisExportCmd.Flags().Uint64("from", 1, "first n to export")
+	oeisExportCmd.Flags().Uint64("to", 10_001, "export n up to but not including this value")
+	oeisExportCmd.Flags().String("file", "", "path of the b-file to write. leave blank for results/bNNNNNN.txt")
+	oeisCmd.AddCommand(oeisVerifyCmd)
+	oeisCmd.AddCommand(oeisExportCmd)
+}
+
+// verifyTerms compares each term to the given stopping time function and returns the index of the first term that
+// does not match, along with the value computed for it
+func verifyTerms(terms []shared.Term, stoppingTime func(n uint64) shared.Result) (int, uint64, bool) {
+	values := make([]uint64, len(terms))
+	var wg sync.WaitGroup
+	workers := runtime.GOMAXPROCS(0)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go (func(worker int, workerCount int) {
+			defer wg.Done()
+			for i := worker; i < len(terms); i += workerCount {
+				values[i] = stoppingTime(terms[i].N).Time
+			}
+		})(w, workers)
+	}
+	wg.Wait()
+	for i := range terms {
+		if values[i] != terms[i].Value {
+			return i, values[i], false
+		}
+	}
+	return 0, 0, true
+}
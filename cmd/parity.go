@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/theriault/collatz/shared"
+)
+
+var (
+	parityCmd = &cobra.Command{
+		Use:   "parity",
+		Short: "Show the parity vector and R′ shapes of n, or the residue class that realises a parity vector or R′ shape",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			n, err := cmd.Flags().GetUint64("n")
+			if err != nil {
+				return err
+			}
+			bits, err := cmd.Flags().GetInt("bits")
+			if err != nil {
+				return err
+			}
+			vector, err := cmd.Flags().GetString("vector")
+			if err != nil {
+				return err
+			}
+			terras, err := cmd.Flags().GetString("terras")
+			if err != nil {
+				return err
+			}
+			shape, err := cmd.Flags().GetString("shape")
+			if err != nil {
+				return err
+			}
+			if bits < 1 || bits > 64 {
+				return fmt.Errorf("--bits must be in the range 1..64: %d", bits)
+			}
+
+			if n > 0 {
+				if err := printParity(n, bits); err != nil {
+					return err
+				}
+			}
+			if vector != "" {
+				parities, err := parseParities(vector)
+				if err != nil {
+					return err
+				}
+				r, b, err := shared.ResidueClass(parities)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("C vector %s: n ≡ %d (mod 2^%d)\n", vector, r, b)
+			}
+			if terras != "" {
+				parities, err := parseParities(terras)
+				if err != nil {
+					return err
+				}
+				r, err := shared.TerrasResidueClass(parities)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("T vector %s: n ≡ %d (mod 2^%d)\n", terras, r, len(parities))
+			}
+			if shape != "" {
+				shapes, err := parseShapes(shape)
+				if err != nil {
+					return err
+				}
+				r, b, err := shared.ShapeResidueClass(shapes)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("R′ shape %s: n ≡ %d (mod 2^%d)\n", shape, r, b)
+			}
+			return nil
+		},
+	}
+)
+
+func init() {
+	parityCmd.Flags().Uint64("n", 0, "show the parity vectors and R′ shapes of n")
+	parityCmd.Flags().Int("bits", 16, "number of parities shown for --n")
+	parityCmd.Flags().String("vector", "", "C parity vector to find the residue class of, e.g. 1010")
+	parityCmd.Flags().String("terras", "", "T parity vector to find the residue class of, e.g. 1100")
+	parityCmd.Flags().String("shape", "", "R′ shapes ν2(x+1):m to find the residue class of, e.g. 0:1,2:1")
+}
+
+// printParity prints the parity vectors of n under C and T, its R′ shapes, and checks that the residue class realising
+// each one contains n
+func printParity(n uint64, bits int) error {
+	shapes, err := shared.RPrimeShapes(n)
+	if err != nil {
+		return fmt.Errorf("the R′ shapes of %d are not representable: %w", n, err)
+	}
+	vector := shared.ParityVector(n, bits)
+	terras := shared.TerrasVector(n, bits)
+	fmt.Printf("n = %d\n", n)
+	fmt.Printf("C vector: %s\n", formatParities(vector))
+	fmt.Printf("T vector: %s\n", formatParities(terras))
+	formatted := make([]string, len(shapes))
+	for i, s := range shapes {
+		formatted[i] = s.String()
+	}
+	fmt.Printf("R′ shapes: %s\n", strings.Join(formatted, ","))
+
+	r, b, err := shared.ResidueClass(vector)
+	if err == nil {
+		fmt.Printf("C vector residue: %d (mod 2^%d), n mod 2^%d = %d\n", r, b, b, n&mask(b))
+	}
+	r, err = shared.TerrasResidueClass(terras)
+	if err == nil {
+		fmt.Printf("T vector residue: %d (mod 2^%d), n mod 2^%d = %d\n", r, bits, bits, n&mask(uint(bits)))
+	}
+	// the shapes of n determine as many bits of n as they have T steps, up to the 64 we can represent
+	for len(shapes) > 0 {
+		r, b, err = shared.ShapeResidueClass(shapes)
+		if err == nil {
+			fmt.Printf("R′ shape residue of the first %d shapes: %d (mod 2^%d), n mod 2^%d = %d\n", len(shapes), r, b, b, n&mask(b))
+			break
+		}
+		shapes = shapes[:len(shapes)-1]
+	}
+	return nil
+}
+
+// mask returns 2^b - 1
+func mask(b uint) uint64 {
+	if b >= 64 {
+		return ^uint64(0)
+	}
+	return 1<<b - 1
+}
+
+func formatParities(vector []uint8) string {
+	var s strings.Builder
+	for _, parity := range vector {
+		s.WriteByte('0' + parity)
+	}
+	return s.String()
+}
+
+func parseParities(s string) ([]uint8, error) {
+	vector := make([]uint8, len(s))
+	for i, c := range s {
+		if c != '0' && c != '1' {
+			return nil, fmt.Errorf("parity vectors should only contain 0 and 1: %s", s)
+		}
+		vector[i] = uint8(c - '0')
+	}
+	return vector, nil
+}
+
+func parseShapes(s string) ([]shared.Shape, error) {
+	shapes := make([]shared.Shape, 0)
+	for _, part := range strings.Split(s, ",") {
+		v, m, ok := strings.Cut(part, ":")
+		if !ok {
+			return nil, fmt.Errorf("shapes should be written ν2(x+1):m: %s", part)
+		}
+		pv, err := strconv.ParseUint(strings.TrimSpace(v), 10, 32)
+		if err != nil {
+			return nil, err
+		}
+		pm, err := strconv.ParseUint(strings.TrimSpace(m), 10, 32)
+		if err != nil {
+			return nil, err
+		}
+		shapes = append(shapes, shared.Shape{V: uint(pv), M: uint(pm)})
+	}
+	return shapes, nil
+}
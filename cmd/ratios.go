@@ -5,8 +5,12 @@ import (
 	"image/color"
 	"log"
 	"math"
+	"math/big"
+	"math/rand"
+	"os"
 	"runtime"
 	"sync"
+	"text/tabwriter"
 
 	"github.com/spf13/cobra"
 	"github.com/theriault/collatz/shared"
@@ -27,6 +31,26 @@ var (
 			if !ok {
 				return fmt.Errorf("invalid value for --fn: %s", fn)
 			}
+			buckets, samples, err := sampleBuckets(cmd)
+			if err != nil {
+				return err
+			}
+			if samples > 0 {
+				seed, err := cmd.Flags().GetInt64("seed")
+				if err != nil {
+					return err
+				}
+				base, _ := cmd.Flags().GetInt64("base")
+				from, _ := cmd.Flags().GetInt("from")
+				to, _ := cmd.Flags().GetInt("to")
+				p := newPlot()
+				p.Title.Text = fmt.Sprintf("Σh(x)/Σ%s, %d Random x per Magnitude", info.Title, samples)
+				p.X.Label.Text = fmt.Sprintf("log%d x", base)
+				p.Y.Label.Text = fmt.Sprintf("Σh(x)/Σ%s", info.Title)
+				sampleRatios(p, buckets, from, samples, seed, info.Title)
+				applyConstraintsToPlot(p, minX, minY, maxX, maxY)
+				return saveToPNG(fmt.Sprintf("ratios_sample_%s_%d_%d_%d.png", info.File, base, from, to), 1500, 900, p)
+			}
 			if power < 1 || power > 20 {
 				return fmt.Errorf("--k must be in the range 1..20: %d", power)
 			}
@@ -39,28 +63,94 @@ var (
 			if err != nil {
 				return err
 			}
+			evaluate, err := engineFlags(cmd)
+			if err != nil {
+				return err
+			}
+
+			perN, err := cmd.Flags().GetBool("per-n")
+			if err != nil {
+				return err
+			}
 
 			p := newPlot()
 			p.Title.Text = fmt.Sprintf("Σh(x)/Σ%s 10^%d", info.Title, power)
-			if graphType == "line" {
+			if perN {
+				if graphType != "scatter" && graphType != "histogram" {
+					return fmt.Errorf("unexpected value for --graph with --per-n: %s", graphType)
+				}
+				p.Title.Text = fmt.Sprintf("h(x)/%s 10^%d", info.Title, power)
+				if graphType == "scatter" {
+					p.X.Label.Text = "x"
+					p.Y.Label.Text = fmt.Sprintf("h(x)/%s", info.Title)
+				} else {
+					p.X.Label.Text = fmt.Sprintf("h(x)/%s", info.Title)
+					p.Y.Label.Text = "Count"
+				}
+				log.Printf("building per-n %s for 10^%d...", graphType, power)
+				if fn == "g" {
+					buildPointwiseRatios(p, graphType, color.NRGBA{R: 0, G: 255, B: 0, A: 128}, limit, group, evaluate("h", limit), evaluate("g", limit), "g(x)")
+				} else {
+					buildPointwiseRatios(p, graphType, color.NRGBA{R: 0, G: 0, B: 255, A: 128}, limit, group, evaluate("h", limit), evaluate("f", limit), "f(x)")
+				}
+			} else if graphType == "line" {
 				p.X.Label.Text = "x"
 				p.Y.Label.Text = fmt.Sprintf("Σh(x)/Σ%s", info.Title)
 				log.Printf("building line graph for 10^%d...", power)
+				var xys plotter.XYs
 				if fn == "g" {
-					buildRatioLine(p, color.NRGBA{R: 0, G: 255, B: 255, A: 128}, limit, group, shared.CollatzStoppingTimeH, shared.CollatzStoppingTimeG, "g(x)")
+					xys = buildRatioLine(p, color.NRGBA{R: 0, G: 255, B: 255, A: 128}, limit, group, evaluate("h", limit), evaluate("g", limit), "g(x)")
 				} else if fn == "f" {
-					buildRatioLine(p, color.NRGBA{R: 0, G: 0, B: 255, A: 128}, limit, group, shared.CollatzStoppingTimeH, shared.CollatzStoppingTimeF, "f(x)")
+					xys = buildRatioLine(p, color.NRGBA{R: 0, G: 0, B: 255, A: 128}, limit, group, evaluate("h", limit), evaluate("f", limit), "f(x)")
 				} else {
 					return fmt.Errorf("unexpected value for --fn: %s", fn)
 				}
+				fit, err := cmd.Flags().GetBool("fit")
+				if err != nil {
+					return err
+				}
+				showPredicted, err := cmd.Flags().GetBool("predicted")
+				if err != nil {
+					return err
+				}
+				var predicted *big.Rat
+				if showPredicted || fit {
+					predicted = predictedLimits()[fn]
+					buildPredictedLimit(p, xys, predicted, info.Title)
+				}
+				if fit {
+					samples, err := cmd.Flags().GetInt("bootstrap")
+					if err != nil {
+						return err
+					}
+					block, err := cmd.Flags().GetInt("block")
+					if err != nil {
+						return err
+					}
+					confidence, err := cmd.Flags().GetFloat64("confidence")
+					if err != nil {
+						return err
+					}
+					seed, err := cmd.Flags().GetInt64("seed")
+					if err != nil {
+						return err
+					}
+					if confidence <= 0 || confidence >= 1 {
+						return fmt.Errorf("--confidence must be in the range (0, 1): %g", confidence)
+					}
+					log.Printf("fitting Σh(x)/Σ%s to L + a/ln(x) with %d bootstrap samples...", info.Title, samples)
+					if err := buildRatioFit(p, xys, samples, block, confidence, seed, predicted, info.Title); err != nil {
+						return err
+					}
+				}
 			} else if graphType == "histogram" {
 				p.X.Label.Text = fmt.Sprintf("Σh(x)/Σ%s", info.Title)
 				p.Y.Label.Text = "Count"
 				log.Printf("building histogram for 10^%d...", power)
 				if fn == "g" {
-					buildRatioHistogram(p, color.NRGBA{R: 0, G: 255, B: 0, A: 128}, limit, group, shared.CollatzStoppingTimeH, shared.CollatzStoppingTimeG, "g(x)")
+					buildRatioHistogram(p, color.NRGBA{R: 0, G: 255, B: 0, A: 128}, limit, group, evaluate("h", limit), evaluate("g", limit), "g(x)")
 				} else if fn == "f" {
-					buildRatioHistogram(p, color.NRGBA{R: 0, G: 0, B: 255, A: 128}, limit, group, shared.CollatzStoppingTimeH, shared.CollatzStoppingTimeF, "f(x)")
+					buildRatioHistogram(p, color.NRGBA{R: 0, G: 0, B: 255, A: 128}, limit, group, evaluate("h", limit), evaluate("f", limit), "f(x)")
 				} else {
 					return fmt.Errorf("unexpected value for --fn: %s", fn)
 				}
@@ -69,6 +159,9 @@ var (
 			}
 			applyConstraintsToPlot(p, minX, minY, maxX, maxY)
 			fileName := fmt.Sprintf("ratios_%s_%s_%d.png", graphType, info.File, power)
+			if perN {
+				fileName = fmt.Sprintf("ratios_pointwise_%s_%s_%d.png", graphType, info.File, power)
+			}
 			return saveToPNG(fileName, 1500, 900, p)
 		},
 	}
@@ -77,15 +170,34 @@ var (
 func init() {
 	ratiosCmd.Flags().StringVar(&fn, "fn", "", "which function to compare h to: f, g")
 	ratiosCmd.Flags().IntVar(&power, "k", 5, "examine n up to 10^k")
-	ratiosCmd.Flags().String("graph", "", "plot using line or histogram")
-	ratiosCmd.Flags().Uint64("group", 5000, "number of x to group into each data point")
+	ratiosCmd.Flags().String("graph", "", "plot using line or histogram, or scatter or histogram with --per-n")
+	ratiosCmd.Flags().Uint64("group", 5000, "number of x to group into each data point, or number of bins for a --per-n histogram")
+	ratiosCmd.Flags().Bool("per-n", false, "plot h(x)/f(x) or h(x)/g(x) for each x instead of the cumulative ratio and report quantiles per decade")
+	ratiosCmd.Flags().Bool("fit", false, "fit the line graph to L + a/ln(x) and overlay the fit and predicted limit")
+	ratiosCmd.Flags().Bool("predicted", false, "overlay the limit predicted by the heuristic model on the line graph and report the gap")
+	ratiosCmd.Flags().Int("bootstrap", 1000, "number of bootstrap samples used for the confidence intervals of the fit")
+	ratiosCmd.Flags().Int("block", 0, "number of consecutive points per bootstrap block. use 0 for √points")
+	ratiosCmd.Flags().Float64("confidence", 0.95, "confidence level of the intervals reported for the fit")
+	ratiosCmd.Flags().Int64("seed", 1, "seed for the bootstrap resampling and the random x of --sample")
+	addSampleFlags(ratiosCmd)
+	addEngineFlags(ratiosCmd)
 	ratiosCmd.Flags().Float64Var(&minX, "min-x", 0, "min x to show on plot. use 0 for min of data")
 	ratiosCmd.Flags().Float64Var(&minY, "min-y", 0, "min y to show on plot. use 0 for min of data")
 	ratiosCmd.Flags().Float64Var(&maxX, "max-x", 0, "max x to show on plot. use 0 for max of data")
 	ratiosCmd.Flags().Float64Var(&maxY, "max-y", 0, "max y to show on plot. use 0 for max of data")
 }
 
-func buildRatioLine(p *plot.Plot, fill color.NRGBA, limit uint64, group uint64, fnN func(n uint64) (uint64, uint64, uint64), fnD func(n uint64) (uint64, uint64, uint64), title string) {
+// predictedLimits returns the limits of Σh(x)/Σf(x) and Σh(x)/Σg(x) predicted by the heuristic model
+func predictedLimits() map[string]*big.Rat {
+	hf, hg, perR, perRPrime := shared.PredictedRatios()
+	log.Printf("heuristic model: %s standard steps per R step, %s per R′ step", perR.RatString(), perRPrime.RatString())
+	return map[string]*big.Rat{
+		"f": hf,
+		"g": hg,
+	}
+}
+
+func buildRatioLine(p *plot.Plot, fill color.NRGBA, limit uint64, group uint64, evaluateN rangeFunction, evaluateD rangeFunction, title string) plotter.XYs {
 	var wg sync.WaitGroup
 	workers := uint64(runtime.GOMAXPROCS(0))
 	numerator := make([]uint64, limit/group)
@@ -94,12 +206,14 @@ func buildRatioLine(p *plot.Plot, fill color.NRGBA, limit uint64, group uint64,
 		wg.Add(1)
 		go (func(worker uint64, workerCount uint64, limit uint64) {
 			defer wg.Done()
+			resultsN := make([]shared.Result, group)
+			resultsD := make([]shared.Result, group)
 			for i := worker * group; i < limit; i += workerCount * group {
+				evaluateN(1+i, resultsN)
+				evaluateD(1+i, resultsD)
 				for j := uint64(0); j < group; j++ {
-					a, _, _ := fnN(1 + i + j)
-					b, _, _ := fnD(1 + i + j)
-					numerator[i/group] += a
-					denominator[i/group] += b
+					numerator[i/group] += resultsN[j].Time
+					denominator[i/group] += resultsD[j].Time
 				}
 			}
 		})(w, workers, limit)
@@ -111,7 +225,7 @@ func buildRatioLine(p *plot.Plot, fill color.NRGBA, limit uint64, group uint64,
 	for i := 0; i < int(limit/group); i++ {
 		numeratorSum += numerator[i]
 		denominatorSum += denominator[i]
-		xys[i].X = float64(uint64(i) * group)
+		xys[i].X = float64(uint64(i+1) * group)
 		xys[i].Y = float64(numeratorSum) / float64(denominatorSum)
 	}
 	h, err := plotter.NewLine(xys)
@@ -123,9 +237,117 @@ func buildRatioLine(p *plot.Plot, fill color.NRGBA, limit uint64, group uint64,
 	p.Legend.TextStyle.Font.Size = 20
 	p.Add(h)
 	applyConstraintsToPlot(p, 1, float64(limit), xys[len(xys)-1].Y, xys[0].Y)
+	return xys
+}
+
+// buildRatioFit fits the cumulative ratio to L + a/ln(x), leaving out x = 1 where the model is undefined, and overlays
+// the fit
+func buildRatioFit(p *plot.Plot, xys plotter.XYs, samples int, block int, confidence float64, seed int64, predicted *big.Rat, title string) error {
+	xs := make([]float64, 0, len(xys))
+	ys := make([]float64, 0, len(xys))
+	for _, xy := range xys {
+		if xy.X > 1 {
+			xs = append(xs, xy.X)
+			ys = append(ys, xy.Y)
+		}
+	}
+	if len(xs) < 2 {
+		return fmt.Errorf("at least 2 points with x > 1 are needed to fit L + a/ln(x): %d", len(xs))
+	}
+	fit, err := shared.BootstrapInverseLog(xs, ys, samples, block, confidence, rand.New(rand.NewSource(seed)))
+	if err != nil {
+		return err
+	}
+	log.Printf("L = %.6f (%g%% CI %.6f..%.6f), a = %.6f (%g%% CI %.6f..%.6f)", fit.Limit, confidence*100, fit.LimitLow, fit.LimitHigh, fit.Slope, confidence*100, fit.SlopeLow, fit.SlopeHigh)
+	limit, _ := predicted.Float64()
+	inside := "outside"
+	if fit.LimitLow <= limit && limit <= fit.LimitHigh {
+		inside = "inside"
+	}
+	log.Printf("predicted limit %s is %.6f from L, %s the confidence interval", predicted.RatString(), limit-fit.Limit, inside)
+
+	curve := plotter.NewFunction(fit.At)
+	curve.XMin = xs[0]
+	curve.XMax = xs[len(xs)-1]
+	curve.Samples = 500
+	curve.Width = vg.Points(1.5)
+	curve.Color = color.NRGBA{R: 255, G: 0, B: 0, A: 255}
+	curve.Dashes = []vg.Length{vg.Points(8), vg.Points(4)}
+	p.Add(curve)
+	p.Legend.Add(fmt.Sprintf("L + a/ln(x), L = %.4f [%.4f, %.4f]", fit.Limit, fit.LimitLow, fit.LimitHigh), curve)
+	return nil
+}
+
+// buildPredictedLimit overlays the limit predicted by the heuristic model and reports its gap to the last point of the
+// cumulative ratio
+func buildPredictedLimit(p *plot.Plot, xys plotter.XYs, predicted *big.Rat, title string) {
+	limit, _ := predicted.Float64()
+	last := xys[len(xys)-1]
+	log.Printf("Σh(x)/Σ%s = %.6f at x = %d, predicted limit %s = %.6f, gap %.6f (%.3f%%)", title, last.Y, uint64(last.X), predicted.RatString(), limit, last.Y-limit, 100*(last.Y-limit)/limit)
+	addPredictedLimit(p, predicted)
+}
+
+// addPredictedLimit overlays a horizontal line at the limit predicted by the heuristic model
+func addPredictedLimit(p *plot.Plot, predicted *big.Rat) {
+	limit, _ := predicted.Float64()
+	reference := plotter.NewFunction(func(float64) float64 { return limit })
+	reference.Width = vg.Points(1.5)
+	reference.Color = color.NRGBA{R: 0, G: 0, B: 0, A: 255}
+	reference.Dashes = []vg.Length{vg.Points(2), vg.Points(4)}
+	p.Add(reference)
+	p.Legend.Add(fmt.Sprintf("predicted limit %s", predicted.RatString()), reference)
+	if limit < p.Y.Min {
+		p.Y.Min = limit
+	}
+	if limit > p.Y.Max {
+		p.Y.Max = limit
+	}
 }
 
-func buildRatioHistogram(p *plot.Plot, fill color.NRGBA, limit uint64, group uint64, fnN func(n uint64) (uint64, uint64, uint64), fnD func(n uint64) (uint64, uint64, uint64), title string) {
+// sampleRatios estimates Σh(x)/Σf(x) or Σh(x)/Σg(x) from uniform random x in each bucket, prints each estimate with its
+// standard error and gap to the predicted limit, and plots them with error bars of two standard errors
+func sampleRatios(p *plot.Plot, buckets []shared.Bucket, from int, samples int, seed int64, title string) {
+	predicted := predictedLimits()[fn]
+	limit, _ := predicted.Float64()
+	fns := []func(n *big.Int) shared.WideResult{shared.CollatzStoppingTimeHWide, WideFunctions[fn]}
+	rng := rand.New(rand.NewSource(seed))
+	points := struct {
+		plotter.XYs
+		plotter.YErrors
+	}{
+		XYs:     make(plotter.XYs, len(buckets)),
+		YErrors: make(plotter.YErrors, len(buckets)),
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "x\tsamples\tΣh(x)/Σ%s\ts.e.\tpredicted\tgap\tgap / s.e.\t\n", title)
+	for i, bucket := range buckets {
+		log.Printf("sampling %d x from %s...", samples, bucket.Label)
+		times, _ := sampleStoppingTimes(bucket.Sample(samples, rng), fns)
+		ratio, se := shared.RatioEstimate(times[0], times[1])
+		fmt.Fprintf(w, "%s\t%d\t%.6f\t%.6f\t%s\t%.6f\t%.2f\t\n", bucket.Label, samples, ratio, se, predicted.RatString(), ratio-limit, (ratio-limit)/se)
+		points.XYs[i] = plotter.XY{X: float64(from+i) + 0.5, Y: ratio}
+		points.YErrors[i].Low, points.YErrors[i].High = 2*se, 2*se
+	}
+	w.Flush()
+
+	s, err := plotter.NewScatter(points)
+	if err != nil {
+		panic(err)
+	}
+	s.Color = color.NRGBA{R: 0, G: 0, B: 255, A: 255}
+	e, err := plotter.NewYErrorBars(points)
+	if err != nil {
+		panic(err)
+	}
+	e.Color = s.Color
+	e.Width = vg.Points(1.5)
+	p.Add(s, e)
+	p.Legend.Add(fmt.Sprintf("Σh(x)/Σ%s ± 2 s.e.", title), s)
+	p.X.Min, p.X.Max = float64(from), float64(from+len(buckets))
+	addPredictedLimit(p, predicted)
+}
+
+func buildRatioHistogram(p *plot.Plot, fill color.NRGBA, limit uint64, group uint64, evaluateN rangeFunction, evaluateD rangeFunction, title string) {
 	var wg sync.WaitGroup
 	workers := uint64(runtime.GOMAXPROCS(0))
 	numerator := make([]uint64, limit)
@@ -134,11 +356,16 @@ func buildRatioHistogram(p *plot.Plot, fill color.NRGBA, limit uint64, group uin
 		wg.Add(1)
 		go (func(worker uint64, workerCount uint64, limit uint64) {
 			defer wg.Done()
-			for i := worker; i < limit; i += workerCount {
-				a, _, _ := fnN(1 + i)
-				b, _, _ := fnD(1 + i)
-				numerator[i] += a
-				denominator[i] += b
+			resultsN := make([]shared.Result, chunk)
+			resultsD := make([]shared.Result, chunk)
+			for from := worker * chunk; from < limit; from += workerCount * chunk {
+				size := chunkSize(from, limit)
+				evaluateN(1+from, resultsN[:size])
+				evaluateD(1+from, resultsD[:size])
+				for j := uint64(0); j < size; j++ {
+					numerator[from+j] += resultsN[j].Time
+					denominator[from+j] += resultsD[j].Time
+				}
 			}
 		})(w, workers, limit)
 	}
@@ -181,3 +408,145 @@ func buildRatioHistogram(p *plot.Plot, fill color.NRGBA, limit uint64, group uin
 	p.Add(h)
 	applyConstraintsToPlot(p, float64(minX), float64(maxX), 0, maxY)
 }
+
+// pointwiseBins is the resolution at which per-n ratios in [0, 1] are counted
+const pointwiseBins = 10_000
+
+// pointwiseQuantiles are the quantiles reported for each decade of per-n ratios
+var pointwiseQuantiles = []float64{0.01, 0.1, 0.25, 0.5, 0.75, 0.9, 0.99}
+
+func buildPointwiseRatios(p *plot.Plot, graphType string, fill color.NRGBA, limit uint64, bins uint64, evaluateN rangeFunction, evaluateD rangeFunction, title string) {
+	type partial struct {
+		xys    plotter.XYs
+		counts [][]uint64
+		sums   []float64
+	}
+	decades := 1
+	for d := uint64(10); d < limit; d *= 10 {
+		decades++
+	}
+	var wg sync.WaitGroup
+	workers := uint64(runtime.GOMAXPROCS(0))
+	completed := make(chan partial, workers)
+	for w := uint64(0); w < workers; w++ {
+		wg.Add(1)
+		go (func(worker uint64, workerCount uint64, limit uint64, completed chan<- partial) {
+			defer wg.Done()
+			result := partial{
+				counts: make([][]uint64, decades),
+				sums:   make([]float64, decades),
+			}
+			for d := range result.counts {
+				result.counts[d] = make([]uint64, pointwiseBins)
+			}
+			if graphType == "scatter" {
+				result.xys = make(plotter.XYs, 0, limit/workerCount+1)
+			}
+			resultsN := make([]shared.Result, chunk)
+			resultsD := make([]shared.Result, chunk)
+			// skip x = 1 where the ratio is 0/0
+			for from := 2 + worker*chunk; from < limit; from += workerCount * chunk {
+				size := chunkSize(from, limit)
+				evaluateN(from, resultsN[:size])
+				evaluateD(from, resultsD[:size])
+				for j := uint64(0); j < size; j++ {
+					i := from + j
+					ratio := float64(resultsN[j].Time) / float64(resultsD[j].Time)
+					d := 0
+					for m := i; m >= 10; m /= 10 {
+						d++
+					}
+					k := int(ratio * pointwiseBins)
+					if k >= pointwiseBins {
+						k = pointwiseBins - 1
+					}
+					result.counts[d][k]++
+					result.sums[d] += ratio
+					if graphType == "scatter" {
+						result.xys = append(result.xys, plotter.XY{X: float64(i), Y: ratio})
+					}
+				}
+			}
+			completed <- result
+		})(w, workers, limit, completed)
+	}
+	wg.Wait()
+	close(completed)
+
+	counts := make([][]uint64, decades)
+	for d := range counts {
+		counts[d] = make([]uint64, pointwiseBins)
+	}
+	sums := make([]float64, decades)
+	for v := range completed {
+		for d := range v.counts {
+			for k, c := range v.counts[d] {
+				counts[d][k] += c
+			}
+			sums[d] += v.sums[d]
+		}
+		if graphType == "scatter" {
+			h, err := plotter.NewScatter(v.xys)
+			if err != nil {
+				panic(err)
+			}
+			h.Color = fill
+			p.Add(h)
+		}
+	}
+
+	fmt.Printf("%-17s %12s %8s", "decade", "count", "mean")
+	for _, q := range pointwiseQuantiles {
+		fmt.Printf(" %7s", fmt.Sprintf("p%g", q*100))
+	}
+	fmt.Println()
+	for d := range counts {
+		total := uint64(0)
+		for _, c := range counts[d] {
+			total += c
+		}
+		if total == 0 {
+			continue
+		}
+		fmt.Printf("%-17s %12d %8.4f", fmt.Sprintf("[10^%d, 10^%d)", d, d+1), total, sums[d]/float64(total))
+		for _, q := range pointwiseQuantiles {
+			fmt.Printf(" %7.4f", pointwiseQuantile(counts[d], total, q))
+		}
+		fmt.Println()
+	}
+
+	if graphType == "histogram" {
+		xys := make(plotter.XYs, pointwiseBins)
+		for k := range xys {
+			xys[k].X = (float64(k) + 0.5) / pointwiseBins
+			for d := range counts {
+				xys[k].Y += float64(counts[d][k])
+			}
+		}
+		if bins > pointwiseBins {
+			bins = pointwiseBins
+		}
+		h, err := plotter.NewHistogram(xys, int(bins))
+		if err != nil {
+			panic(err)
+		}
+		h.LineStyle.Width = 0
+		h.FillColor = fill
+		p.Add(h)
+	}
+	p.Legend.TextStyle.Font.Size = 20
+	p.Legend.Add(fmt.Sprintf("h(x)/%s", title))
+}
+
+// pointwiseQuantile returns the center of the bin containing the q-th quantile of the counted ratios
+func pointwiseQuantile(counts []uint64, total uint64, q float64) float64 {
+	target := uint64(math.Ceil(q * float64(total)))
+	seen := uint64(0)
+	for k, c := range counts {
+		seen += c
+		if seen >= target && seen > 0 {
+			return (float64(k) + 0.5) / pointwiseBins
+		}
+	}
+	return 1
+}
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/theriault/collatz/shared"
+)
+
+var (
+	recordsCmd = &cobra.Command{
+		Use:   "records",
+		Short: "List delay records (stopping time exceeds every smaller n) or path records (peak exceeds every smaller n)",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if fn != "f" && fn != "g" && fn != "h" {
+				return fmt.Errorf("--fn should be f, g or h")
+			}
+			info := Types[fn]
+			if power < 1 || power > 20 {
+				return fmt.Errorf("--k must be in the range 1..20: %d", power)
+			}
+			limit := uint64(math.Pow10(power))
+			recordType, err := cmd.Flags().GetString("type")
+			if err != nil {
+				return err
+			}
+			resumeN, err := cmd.Flags().GetUint64("resume-n")
+			if err != nil {
+				return err
+			}
+			resumeValue, err := cmd.Flags().GetUint64("resume-value")
+			if err != nil {
+				return err
+			}
+			check, err := cmd.Flags().GetString("check")
+			if err != nil {
+				return err
+			}
+
+			stoppingTime := batched(fn, limit)
+			var value func(n uint64) uint64
+			if recordType == "delay" {
+				value = func(n uint64) uint64 {
+					return stoppingTime(n).Time
+				}
+			} else if recordType == "path" {
+				value = func(n uint64) uint64 {
+					return stoppingTime(n).Max
+				}
+			} else {
+				return fmt.Errorf("unexpected value for --type: %s", recordType)
+			}
+
+			// without a known last record we start from n = 1, which is always a record
+			from := uint64(1)
+			best := uint64(0)
+			var records []shared.Record
+			if resumeN > 0 {
+				if resumeValue == 0 {
+					resumeValue = value(resumeN)
+				}
+				from = resumeN + 1
+				best = resumeValue
+				log.Printf("resuming %s records of %s after n = %d with value %d", recordType, info.Title, resumeN, resumeValue)
+			} else {
+				records = append(records, shared.Record{N: 1, Value: value(1)})
+				best = records[0].Value
+				from = 2
+			}
+			log.Printf("scanning %s records of %s from %d..10^%d", recordType, info.Title, from, power)
+			records = append(records, shared.FindRecords(from, limit, best, value)...)
+
+			for _, record := range records {
+				fmt.Printf("%d %d\n", record.N, record.Value)
+			}
+			if check != "" {
+				if fn != "f" {
+					return fmt.Errorf("--check is only available for f, the published records are for the standard map")
+				}
+				f, err := os.Open(check)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				terms, err := shared.ReadBFile(f)
+				if err != nil {
+					return fmt.Errorf("%s: %w", check, err)
+				}
+				known := make([]uint64, len(terms))
+				for i, term := range terms {
+					known[i] = term.Value
+				}
+				matched, err := shared.CheckRecords(records, known, resumeN, limit)
+				if err != nil {
+					return fmt.Errorf("%s: %w", check, err)
+				}
+				log.Printf("%d records match the published values", matched)
+			}
+			return nil
+		},
+	}
+)
+
+func init() {
+	recordsCmd.Flags().StringVar(&fn, "fn", "", "which function to find records for: f, g, h")
+	recordsCmd.Flags().IntVar(&power, "k", 5, "examine n up to 10^k")
+	recordsCmd.Flags().String("type", "delay", "record type: delay | path")
+	recordsCmd.Flags().Uint64("resume-n", 0, "resume scanning after this known record. use 0 to start at 1")
+	recordsCmd.Flags().Uint64("resume-value", 0, "value of the record given by --resume-n. use 0 to compute it")
+	recordsCmd.Flags().String("check", "", "b-file of the published records to verify the n found for f against: A006877 for delay, A006884 for path")
+}
@@ -16,9 +16,21 @@ func Execute() error {
 	rootCmd.AddCommand(maxCmd)
 	rootCmd.AddCommand(ratiosCmd)
 	rootCmd.AddCommand(compareCmd)
+	rootCmd.AddCommand(recordsCmd)
+	rootCmd.AddCommand(oeisCmd)
+	rootCmd.AddCommand(accelCmd)
+	rootCmd.AddCommand(sieveCmd)
+	rootCmd.AddCommand(verifyCmd)
+	rootCmd.AddCommand(cyclesCmd)
+	rootCmd.AddCommand(inverseCmd)
+	rootCmd.AddCommand(treeCmd)
+	rootCmd.AddCommand(levelsetCmd)
+	rootCmd.AddCommand(parityCmd)
+	rootCmd.AddCommand(statsCmd)
+	rootCmd.AddCommand(benchCmd)
 	return rootCmd.Execute()
 }
 
 func init() {
-
+	rootCmd.PersistentFlags().Uint64Var(&cacheSize, "cache", 1<<22, "number of results cached when evaluating a range of x, using 16 bytes each, up to 2^26. use 0 to disable")
 }
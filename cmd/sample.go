@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"runtime"
+	"sync"
+
+	"github.com/spf13/cobra"
+	"github.com/theriault/collatz/shared"
+)
+
+// addSampleFlags adds the flags for sampling random n instead of examining 1..10^k
+func addSampleFlags(cmd *cobra.Command) {
+	cmd.Flags().Int("sample", 0, "number of random n to sample per magnitude instead of examining 1..10^k. use 0 to examine every n")
+	cmd.Flags().Int("from", 20, "sample n from base^from with --sample")
+	cmd.Flags().Int("to", 30, "sample n up to base^to with --sample")
+	cmd.Flags().Int64("base", 10, "base of the magnitudes sampled with --sample: 2 | 10")
+}
+
+// sampleBuckets returns the magnitude buckets given by the sampling flags, and the number of samples per bucket, which
+// is 0 if sampling was not requested
+func sampleBuckets(cmd *cobra.Command) ([]shared.Bucket, int, error) {
+	samples, err := cmd.Flags().GetInt("sample")
+	if err != nil || samples == 0 {
+		return nil, 0, err
+	}
+	from, err := cmd.Flags().GetInt("from")
+	if err != nil {
+		return nil, 0, err
+	}
+	to, err := cmd.Flags().GetInt("to")
+	if err != nil {
+		return nil, 0, err
+	}
+	base, err := cmd.Flags().GetInt64("base")
+	if err != nil {
+		return nil, 0, err
+	}
+	if samples < 2 {
+		return nil, 0, fmt.Errorf("--sample must be at least 2 to estimate a standard error: %d", samples)
+	}
+	if base != 2 && base != 10 {
+		return nil, 0, fmt.Errorf("--base should be 2 or 10: %d", base)
+	}
+	if from < 0 || to <= from {
+		return nil, 0, fmt.Errorf("--from and --to must satisfy 0 ≤ from < to: %d, %d", from, to)
+	}
+	return shared.MagnitudeBuckets(base, from, to), samples, nil
+}
+
+// sampleStoppingTimes evaluates each of the wide functions on each n in parallel, and returns the reduced time of every
+// n for each function, along with ln(n)
+func sampleStoppingTimes(values []*big.Int, fns []func(n *big.Int) shared.WideResult) ([][]float64, []float64) {
+	times := make([][]float64, len(fns))
+	for i := range times {
+		times[i] = make([]float64, len(values))
+	}
+	logs := make([]float64, len(values))
+	var wg sync.WaitGroup
+	workers := runtime.GOMAXPROCS(0)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go (func(worker int, workerCount int) {
+			defer wg.Done()
+			for i := worker; i < len(values); i += workerCount {
+				for j, fn := range fns {
+					times[j][i] = float64(fn(values[i]).Time)
+				}
+				logs[i] = bigLog(values[i])
+			}
+		})(w, workers)
+	}
+	wg.Wait()
+	return times, logs
+}
+
+// bigLog returns ln(n) for n > 0, which may be too large to convert to a float64
+func bigLog(n *big.Int) float64 {
+	shift := 0
+	if n.BitLen() > 64 {
+		shift = n.BitLen() - 64
+	}
+	top, _ := new(big.Float).SetInt(new(big.Int).Rsh(n, uint(shift))).Float64()
+	return math.Log(top) + float64(shift)*math.Ln2
+}
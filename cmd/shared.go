@@ -3,8 +3,10 @@ package cmd
 import (
 	"image/png"
 	"log"
+	"math/big"
 	"os"
 
+	"github.com/theriault/collatz/shared"
 	"gonum.org/v1/plot"
 	"gonum.org/v1/plot/vg"
 	"gonum.org/v1/plot/vg/draw"
@@ -18,10 +20,12 @@ var minX float64
 var minY float64
 var maxX float64
 var maxY float64
+var cacheSize uint64
 
 type Info struct {
 	Title string
 	File  string
+	OEIS  string
 }
 
 // Types are the main stopping time functions we will examine
@@ -33,17 +37,85 @@ var Types = map[string]Info{
 	"f": {
 		Title: "f(x)",
 		File:  "f",
+		OEIS:  "A006577",
 	},
 	"g": {
 		Title: "g(x)",
 		File:  "g",
+		OEIS:  "A286380",
 	},
 	"h": {
 		Title: "h(x)",
 		File:  "h",
+		OEIS:  "A160541",
 	},
 }
 
+// Functions are the stopping time functions for each of the Types, except the combined type. They exit if an orbit
+// leaves the range of uint64, since every plot and statistic built on the result would silently be wrong.
+var Functions = map[string]func(n uint64) shared.Result{
+	"f": checkOverflow(shared.CollatzStoppingTimeF),
+	"g": checkOverflow(shared.CollatzStoppingTimeG),
+	"h": checkOverflow(shared.CollatzStoppingTimeH),
+}
+
+// checkOverflow wraps a stopping time function to exit when the orbit of n leaves the range of uint64
+func checkOverflow(fn func(n uint64) shared.Result) func(n uint64) shared.Result {
+	return func(n uint64) shared.Result {
+		r := fn(n)
+		exitOnOverflow(n, r)
+		return r
+	}
+}
+
+// exitOnOverflow exits if r, the result of n, left the range of uint64
+func exitOnOverflow(n uint64, r shared.Result) {
+	if r.Overflow {
+		log.Fatalf("the orbit of %d leaves the range of uint64, after %d standard steps", n, r.StandardTime)
+	}
+}
+
+// Batches create a shared.Batch for each of the Types, except the combined type
+var Batches = map[string]func(size uint64) *shared.Batch{
+	"f": shared.NewBatchF,
+	"g": shared.NewBatchG,
+	"h": shared.NewBatchH,
+}
+
+// batched returns the stopping time function of the given type evaluated with a shared.Batch, whose cache covers n up
+// to limit or --cache, whichever is smaller. Like Functions, it exits if an orbit leaves the range of uint64. With
+// --cache 0 it returns the function from Functions.
+func batched(name string, limit uint64) func(n uint64) shared.Result {
+	if cacheSize == 0 {
+		return Functions[name]
+	}
+	return checkOverflow(newBatch(name, limit).Evaluate)
+}
+
+// newBatch returns a shared.Batch for the given type, whose cache covers n up to limit or --cache, whichever is
+// smaller
+func newBatch(name string, limit uint64) *shared.Batch {
+	size := limit
+	if size > cacheSize {
+		size = cacheSize
+	}
+	if size > shared.MaxBatchCache {
+		size = shared.MaxBatchCache
+	}
+	if size < 2 {
+		size = 2
+	}
+	log.Printf("caching %s for 1..%d...", Types[name].Title, size-1)
+	return Batches[name](size)
+}
+
+// WideFunctions are the arbitrary precision counterparts of Functions
+var WideFunctions = map[string]func(n *big.Int) shared.WideResult{
+	"f": shared.CollatzStoppingTimeFWide,
+	"g": shared.CollatzStoppingTimeGWide,
+	"h": shared.CollatzStoppingTimeHWide,
+}
+
 // saveToPNG is a helper function to save a given plot to the filesystem
 func saveToPNG(fileName string, width, height int, p *plot.Plot) error {
 	fullPath := "results/" + fileName
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/theriault/collatz/shared"
+)
+
+var (
+	sieveCmd = &cobra.Command{
+		Use:   "sieve",
+		Short: "Generate the residue classes mod 2^k that do not provably descend within k steps, and use them to verify convergence",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			bits, err := cmd.Flags().GetUint("bits")
+			if err != nil {
+				return err
+			}
+			if bits < 1 || bits > 28 {
+				return fmt.Errorf("--bits must be in the range 1..28: %d", bits)
+			}
+			verify, err := cmd.Flags().GetBool("verify")
+			if err != nil {
+				return err
+			}
+			list, err := cmd.Flags().GetBool("list")
+			if err != nil {
+				return err
+			}
+
+			log.Printf("building sieve mod 2^%d...", bits)
+			sieve := shared.NewSieve(bits)
+			log.Printf("%d of %d residue classes survive (%.4f%%)", len(sieve.Survivors), uint64(1)<<bits, 100*float64(len(sieve.Survivors))/float64(uint64(1)<<bits))
+			if list {
+				for _, b := range sieve.Survivors {
+					fmt.Println(b)
+				}
+			}
+			if !verify {
+				return nil
+			}
+			if power < 1 || power > 20 {
+				return fmt.Errorf("--k must be in the range 1..20: %d", power)
+			}
+			limit := uint64(math.Pow10(power))
+
+			log.Printf("verifying every x from 1..10^%d drops below itself using the sieve...", power)
+			start := time.Now()
+			iterated, failed := verifyDescent(limit, sieve)
+			sieveTime := time.Since(start)
+			if failed != 0 {
+				return fmt.Errorf("could not verify %d, its trajectory leaves the range of uint64", failed)
+			}
+			log.Printf("sieve: %s, %.0f x/s, iterated %d of %d x (%.4f%%)", sieveTime, float64(limit-1)/sieveTime.Seconds(), iterated, limit-1, 100*float64(iterated)/float64(limit-1))
+
+			log.Printf("computing f(x) from 1..10^%d for comparison...", power)
+			f := Functions["f"]
+			times := make([]uint64, limit)
+			bruteTime := timeEach(limit, func(n uint64) {
+				times[n] = f(n).Time
+			})
+			log.Printf("brute force: %s, %.0f x/s, %.1fx slower", bruteTime, float64(limit-1)/bruteTime.Seconds(), bruteTime.Seconds()/sieveTime.Seconds())
+			return nil
+		},
+	}
+)
+
+func init() {
+	sieveCmd.Flags().Uint("bits", 16, "generate residue classes mod 2^bits")
+	sieveCmd.Flags().Bool("list", false, "print the surviving residue classes")
+	sieveCmd.Flags().Bool("verify", false, "verify every x up to 10^k reaches 1 using the sieve and compare the throughput to f")
+	sieveCmd.Flags().IntVar(&power, "k", 5, "examine n up to 10^k")
+}
+
+// verifyDescent shows each x in 2..limit drops below itself, which by induction means every x reaches 1. It returns
+// the number of x that had to be iterated, and an x whose trajectory left the range of uint64, or 0.
+func verifyDescent(limit uint64, sieve *shared.Sieve) (uint64, uint64) {
+	var wg sync.WaitGroup
+	workers := uint64(runtime.GOMAXPROCS(0))
+	iterated := make([]uint64, workers)
+	failed := make([]uint64, workers)
+	for w := uint64(0); w < workers; w++ {
+		wg.Add(1)
+		go (func(worker uint64, workerCount uint64, limit uint64) {
+			defer wg.Done()
+			for i := 2 + worker; i < limit; i += workerCount {
+				if sieve.Descends(i) {
+					continue
+				}
+				iterated[worker]++
+				if _, ok, _ := shared.DescentTime(i); !ok {
+					failed[worker] = i
+					return
+				}
+			}
+		})(w, workers, limit)
+	}
+	wg.Wait()
+	total := uint64(0)
+	for w := range iterated {
+		total += iterated[w]
+		if failed[w] != 0 {
+			return total, failed[w]
+		}
+	}
+	return total, 0
+}
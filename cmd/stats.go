@@ -0,0 +1,233 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"runtime"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/theriault/collatz/shared"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/palette"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+var (
+	statsCmd = &cobra.Command{
+		Use:   "stats",
+		Short: "Report the distribution of ν2(x+1) and m over the steps of R′, or the odd and even steps and coefficient stopping time of f, g, h",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if power < 1 || power > 20 {
+				return fmt.Errorf("--k must be in the range 1..20: %d", power)
+			}
+			limit := uint64(math.Pow10(power))
+			mode, err := cmd.Flags().GetString("mode")
+			if err != nil {
+				return err
+			}
+			size, err := cmd.Flags().GetInt("size")
+			if err != nil {
+				return err
+			}
+			if size < 1 || size > 64 {
+				return fmt.Errorf("--size must be in the range 1..64: %d", size)
+			}
+			if mode == "valuations" {
+				log.Printf("counting ν2(x+1) and m over the steps of R′ from 1..10^%d...", power)
+				counts := countValuations(limit)
+				printValuations(counts, size)
+				p := newPlot()
+				p.Title.Text = fmt.Sprintf("-log2 P(ν2(x+1), m) over R′ Steps 10^%d (model in parentheses)", power)
+				buildValuationHeatMap(p, counts, size)
+				return saveToPNG(fmt.Sprintf("stats_valuations_%d.png", power), 1200, 1100, p)
+			}
+			if mode == "steps" {
+				names := []string{"f", "g", "h"}
+				if fn != "" {
+					names = []string{fn}
+				}
+				log.Printf("counting odd and even steps from 1..10^%d...", power)
+				w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+				fmt.Fprintln(w, "fn\tΣa\tΣb\tΣb/Σa\tlog2(3)\tmean coefficient time\tmax coefficient time\tat x\t")
+				for _, name := range names {
+					if _, ok := Functions[name]; !ok {
+						return fmt.Errorf("invalid value for --fn: %s", name)
+					}
+					s := sumStepCounts(limit, batched(name, limit))
+					fmt.Fprintf(w, "%s\t%d\t%d\t%.6f\t%.6f\t%.4f\t%d\t%d\t\n", Types[name].Title, s.odd, s.even, float64(s.even)/float64(s.odd),
+						math.Log2(3), float64(s.coefficientTime)/float64(limit-1), s.maxCoefficientTime, s.maxCoefficientN)
+				}
+				w.Flush()
+				return nil
+			}
+			return fmt.Errorf("unexpected value for --mode: %s", mode)
+		},
+	}
+)
+
+func init() {
+	statsCmd.Flags().IntVar(&power, "k", 5, "examine n up to 10^k")
+	statsCmd.Flags().StringVar(&fn, "fn", "", "which function to count steps with for --mode steps: f, g, h. leave blank for all")
+	statsCmd.Flags().String("mode", "valuations", "statistic to report: valuations | steps")
+	statsCmd.Flags().Int("size", 8, "largest ν2(x+1) and m shown individually in the table and heatmap")
+}
+
+func countValuations(limit uint64) *shared.ValuationCounts {
+	var wg sync.WaitGroup
+	workers := uint64(runtime.GOMAXPROCS(0))
+	counts := make([]shared.ValuationCounts, workers)
+	for w := uint64(0); w < workers; w++ {
+		wg.Add(1)
+		go (func(worker uint64, workerCount uint64, limit uint64) {
+			defer wg.Done()
+			for i := 1 + worker; i < limit; i += workerCount {
+				if !counts[worker].Add(i) {
+					log.Fatalf("the orbit of %d leaves the range of uint64", i)
+				}
+			}
+		})(w, workers, limit)
+	}
+	wg.Wait()
+	total := &shared.ValuationCounts{}
+	for w := range counts {
+		total.Merge(&counts[w])
+	}
+	return total
+}
+
+// stepSums are the totals of the odd and even steps and coefficient stopping times from 1..limit, and the largest coefficient stopping time
+type stepSums struct {
+	odd                uint64
+	even               uint64
+	coefficientTime    uint64
+	maxCoefficientTime uint64
+	maxCoefficientN    uint64
+}
+
+func sumStepCounts(limit uint64, fn func(n uint64) shared.Result) stepSums {
+	var wg sync.WaitGroup
+	workers := uint64(runtime.GOMAXPROCS(0))
+	sums := make([]stepSums, workers)
+	for w := uint64(0); w < workers; w++ {
+		wg.Add(1)
+		go (func(worker uint64, workerCount uint64, limit uint64) {
+			defer wg.Done()
+			for i := 1 + worker; i < limit; i += workerCount {
+				c := fn(i)
+				sums[worker].odd += c.Odd
+				sums[worker].even += c.Even
+				sums[worker].coefficientTime += c.CoefficientTime
+				if c.CoefficientTime > sums[worker].maxCoefficientTime || (c.CoefficientTime == sums[worker].maxCoefficientTime && i < sums[worker].maxCoefficientN) {
+					sums[worker].maxCoefficientTime = c.CoefficientTime
+					sums[worker].maxCoefficientN = i
+				}
+			}
+		})(w, workers, limit)
+	}
+	wg.Wait()
+	total := stepSums{}
+	for _, s := range sums {
+		total.odd += s.odd
+		total.even += s.even
+		total.coefficientTime += s.coefficientTime
+		if s.maxCoefficientTime > total.maxCoefficientTime || (s.maxCoefficientTime == total.maxCoefficientTime && s.maxCoefficientN < total.maxCoefficientN) {
+			total.maxCoefficientTime = s.maxCoefficientTime
+			total.maxCoefficientN = s.maxCoefficientN
+		}
+	}
+	return total
+}
+
+// printValuations prints the observed and predicted probability of each v, m ≤ size, followed by the marginal
+// distributions of v and m, and the total variation distance between the observed and predicted distributions
+func printValuations(counts *shared.ValuationCounts, size int) {
+	total := float64(counts.Total())
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ν2(x+1)\tm\tsteps\tobserved\tmodel\tobserved / model\t")
+	distance := 0.0
+	other := uint64(0)
+	marginalV := make([]uint64, len(counts))
+	marginalM := make([]uint64, len(counts))
+	for v := range counts {
+		for m := range counts[v] {
+			observed := float64(counts[v][m]) / total
+			model := shared.ValuationProbability(v, m)
+			distance += math.Abs(observed-model) / 2
+			marginalV[v] += counts[v][m]
+			marginalM[m] += counts[v][m]
+			if v < 1 || m < 1 || v > size || m > size {
+				other += counts[v][m]
+				continue
+			}
+			fmt.Fprintf(w, "%d\t%d\t%d\t%.6f\t%.6f\t%.4f\t\n", v, m, counts[v][m], observed, model, observed/model)
+		}
+	}
+	fmt.Fprintf(w, "other\t\t%d\t%.6f\t\t\t\n", other, float64(other)/total)
+	w.Flush()
+	fmt.Println()
+
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "k\tP(ν2(x+1) = k)\t2^-k\tP(m = k)\t2^-k\t")
+	for k := 1; k <= size; k++ {
+		fmt.Fprintf(w, "%d\t%.6f\t%.6f\t%.6f\t%.6f\t\n", k, float64(marginalV[k])/total, math.Ldexp(1, -k), float64(marginalM[k])/total, math.Ldexp(1, -k))
+	}
+	w.Flush()
+	fmt.Printf("\n%d steps, total variation distance from the model: %.6f\n", counts.Total(), distance)
+}
+
+// valuationGrid is the -log2 of the observed probability of each v, m ≤ size, which the model predicts is v + m
+type valuationGrid struct {
+	counts *shared.ValuationCounts
+	total  float64
+	size   int
+}
+
+func (g valuationGrid) Dims() (c, r int) { return g.size, g.size }
+func (g valuationGrid) X(c int) float64  { return float64(c + 1) }
+func (g valuationGrid) Y(r int) float64  { return float64(r + 1) }
+func (g valuationGrid) Z(c, r int) float64 {
+	count := g.counts[c+1][r+1]
+	if count == 0 {
+		return math.NaN()
+	}
+	return -math.Log2(float64(count) / g.total)
+}
+
+func buildValuationHeatMap(p *plot.Plot, counts *shared.ValuationCounts, size int) {
+	grid := valuationGrid{counts: counts, total: float64(counts.Total()), size: size}
+	h := plotter.NewHeatMap(grid, palette.Heat(64, 1))
+	p.Add(h)
+	p.X.Label.Text = "ν2(x+1)"
+	p.Y.Label.Text = "m"
+	p.X.Min, p.X.Max = 0.5, float64(size)+0.5
+	p.Y.Min, p.Y.Max = 0.5, float64(size)+0.5
+	ticks := make([]plot.Tick, size)
+	for i := range ticks {
+		ticks[i] = plot.Tick{Value: float64(i + 1), Label: fmt.Sprint(i + 1)}
+	}
+	p.X.Tick.Marker = plot.ConstantTicks(ticks)
+	p.Y.Tick.Marker = plot.ConstantTicks(ticks)
+
+	labels := plotter.XYLabels{}
+	for v := 1; v <= size; v++ {
+		for m := 1; m <= size; m++ {
+			labels.XYs = append(labels.XYs, plotter.XY{X: float64(v), Y: float64(m)})
+			labels.Labels = append(labels.Labels, fmt.Sprintf("%.2f\n(%d)", grid.Z(v-1, m-1), v+m))
+		}
+	}
+	l, err := plotter.NewLabels(labels)
+	if err != nil {
+		panic(err)
+	}
+	for i := range l.TextStyle {
+		l.TextStyle[i].Font.Size = 16
+		l.TextStyle[i].XAlign = draw.XCenter
+		l.TextStyle[i].YAlign = draw.YCenter
+	}
+	p.Add(l)
+}
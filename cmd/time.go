@@ -5,8 +5,12 @@ import (
 	"image/color"
 	"log"
 	"math"
+	"math/big"
+	"math/rand"
+	"os"
 	"runtime"
 	"sync"
+	"text/tabwriter"
 
 	"github.com/spf13/cobra"
 	"github.com/theriault/collatz/shared"
@@ -23,6 +27,18 @@ var (
 			if !ok {
 				return fmt.Errorf("invalid value for --fn: %s", fn)
 			}
+			buckets, samples, err := sampleBuckets(cmd)
+			if err != nil {
+				return err
+			}
+			if samples > 0 {
+				seed, err := cmd.Flags().GetInt64("seed")
+				if err != nil {
+					return err
+				}
+				sampleTime(buckets, samples, seed)
+				return nil
+			}
 			if power < 1 || power > 20 {
 				return fmt.Errorf("--k must be in the range 1..20: %d", power)
 			}
@@ -31,49 +47,65 @@ var (
 			if err != nil {
 				return err
 			}
+			metric, err := cmd.Flags().GetString("metric")
+			if err != nil {
+				return err
+			}
+			metricTitle, ok := metricTitles[metric]
+			if !ok {
+				return fmt.Errorf("unexpected value for --metric: %s", metric)
+			}
+			evaluate, err := engineFlags(cmd)
+			if err != nil {
+				return err
+			}
+			value := metrics[metric]
 
 			p := newPlot()
-			p.Title.Text = fmt.Sprintf("%s Total Stopping Time 10^%d", info.Title, power)
+			p.Title.Text = fmt.Sprintf("%s %s 10^%d", info.Title, metricTitle, power)
 			if graphType == "histogram" {
-				p.X.Label.Text = "Stopping Time"
+				p.X.Label.Text = metricTitle
 				p.Y.Label.Text = "Count"
 			} else if graphType == "scatter" {
 				p.X.Label.Text = "x"
-				p.Y.Label.Text = "Total Stopping Time"
+				p.Y.Label.Text = metricTitle
 			}
 			p.Y.Min = 0
 			p.X.Min = 1
 			if graphType == "histogram" {
 				if fn == "" || fn == "f" {
 					log.Printf("building f(x) histogram for 10^%d...", power)
-					buildHistogram(p, color.NRGBA{R: 255, G: 0, B: 0, A: 128}, limit, shared.CollatzStoppingTimeF, "f(x)")
+					buildHistogram(p, color.NRGBA{R: 255, G: 0, B: 0, A: 128}, limit, evaluate("f", limit), value, "f(x)")
 				}
 				if fn == "" || fn == "g" {
 					log.Printf("building g(x) histogram for 10^%d...", power)
-					buildHistogram(p, color.NRGBA{R: 0, G: 255, B: 0, A: 128}, limit, shared.CollatzStoppingTimeG, "g(x)")
+					buildHistogram(p, color.NRGBA{R: 0, G: 255, B: 0, A: 128}, limit, evaluate("g", limit), value, "g(x)")
 				}
 				if fn == "" || fn == "h" {
 					log.Printf("building h(x) histogram for 10^%d...", power)
-					buildHistogram(p, color.NRGBA{R: 0, G: 0, B: 255, A: 128}, limit, shared.CollatzStoppingTimeH, "h(x)")
+					buildHistogram(p, color.NRGBA{R: 0, G: 0, B: 255, A: 128}, limit, evaluate("h", limit), value, "h(x)")
 				}
 			} else if graphType == "scatter" {
 				if fn == "" || fn == "f" {
 					log.Printf("building f(x) scatter for 10^%d...", power)
-					buildTime(p, color.NRGBA{R: 255, G: 0, B: 0, A: 128}, limit, shared.CollatzStoppingTimeF, "f(x)")
+					buildTime(p, color.NRGBA{R: 255, G: 0, B: 0, A: 128}, limit, evaluate("f", limit), value, "f(x)")
 				}
 				if fn == "" || fn == "g" {
 					log.Printf("building g(x) scatter for 10^%d...", power)
-					buildTime(p, color.NRGBA{R: 0, G: 255, B: 0, A: 128}, limit, shared.CollatzStoppingTimeG, "g(x)")
+					buildTime(p, color.NRGBA{R: 0, G: 255, B: 0, A: 128}, limit, evaluate("g", limit), value, "g(x)")
 				}
 				if fn == "" || fn == "h" {
 					log.Printf("building h(x) scatter for 10^%d...", power)
-					buildTime(p, color.NRGBA{R: 0, G: 0, B: 255, A: 128}, limit, shared.CollatzStoppingTimeH, "h(x)")
+					buildTime(p, color.NRGBA{R: 0, G: 0, B: 255, A: 128}, limit, evaluate("h", limit), value, "h(x)")
 				}
 			} else {
 				return fmt.Errorf("unexpected value for --graph: %s", graphType)
 			}
 			applyConstraintsToPlot(p, minX, minY, maxX, maxY)
 			fileName := fmt.Sprintf("time_%s_%s_%d.png", graphType, info.File, power)
+			if metric != "time" {
+				fileName = fmt.Sprintf("time_%s_%s_%s_%d.png", metric, graphType, info.File, power)
+			}
 			return saveToPNG(fileName, 1500, 900, p)
 		},
 	}
@@ -83,27 +115,48 @@ func init() {
 	timeCmd.Flags().StringVar(&fn, "fn", "", "which function to plot: f (standard), g (reduced), h (main result). leave blank to plot all")
 	timeCmd.Flags().IntVar(&power, "k", 5, "examine n up to 10^k")
 	timeCmd.Flags().String("graph", "", "graph type: scatter | histogram")
+	timeCmd.Flags().String("metric", "time", "value to plot: time (total stopping time) | odd | even | coefficient (coefficient stopping time)")
+	addEngineFlags(timeCmd)
+	addSampleFlags(timeCmd)
+	timeCmd.Flags().Int64("seed", 1, "seed for the random n of --sample")
 	timeCmd.Flags().Float64Var(&minX, "min-x", 0, "min x to show on plot. use 0 for min of data")
 	timeCmd.Flags().Float64Var(&minY, "min-y", 0, "min y to show on plot. use 0 for min of data")
 	timeCmd.Flags().Float64Var(&maxX, "max-x", 0, "max x to show on plot. use 0 for max of data")
 	timeCmd.Flags().Float64Var(&maxY, "max-y", 0, "max y to show on plot. use 0 for max of data")
 }
 
-func buildTime(p *plot.Plot, fill color.NRGBA, limit uint64, fn func(n uint64) (uint64, uint64, uint64), title string) {
+// metricTitles are the values that can be plotted for each x with --metric
+var metricTitles = map[string]string{
+	"time":        "Total Stopping Time",
+	"odd":         "Odd Steps",
+	"even":        "Even Steps",
+	"coefficient": "Coefficient Stopping Time",
+}
+
+// metrics select the value plotted for each x from its Result
+var metrics = map[string]func(r shared.Result) uint64{
+	"time":        func(r shared.Result) uint64 { return r.Time },
+	"odd":         func(r shared.Result) uint64 { return r.Odd },
+	"even":        func(r shared.Result) uint64 { return r.Even },
+	"coefficient": func(r shared.Result) uint64 { return r.CoefficientTime },
+}
+
+func buildTime(p *plot.Plot, fill color.NRGBA, limit uint64, evaluate rangeFunction, value func(r shared.Result) uint64, title string) {
 	var wg sync.WaitGroup
 	workers := uint64(runtime.GOMAXPROCS(0))
 	completed := make(chan plotter.XYs, workers)
-	for w := uint64(1); w <= workers; w++ {
+	for w := uint64(0); w < workers; w++ {
 		wg.Add(1)
 		go (func(worker uint64, workerCount uint64, limit uint64, completed chan<- plotter.XYs) {
 			defer wg.Done()
-			xys := make(plotter.XYs, limit/workerCount+1)
-			j := 0
-			for i := worker; i < limit; i += workerCount {
-				a, _, _ := fn(i)
-				xys[j].X = float64(i)
-				xys[j].Y = float64(a)
-				j++
+			xys := make(plotter.XYs, 0, limit/workerCount+chunk)
+			results := make([]shared.Result, chunk)
+			for from := 1 + worker*chunk; from < limit; from += workerCount * chunk {
+				batch := results[:chunkSize(from, limit)]
+				evaluate(from, batch)
+				for j, r := range batch {
+					xys = append(xys, plotter.XY{X: float64(from + uint64(j)), Y: float64(value(r))})
+				}
 			}
 			completed <- xys
 		})(w, workers, limit, completed)
@@ -131,18 +184,22 @@ func buildTime(p *plot.Plot, fill color.NRGBA, limit uint64, fn func(n uint64) (
 	p.Legend.Add(fmt.Sprintf("max %s = %d", title, int(maxY)))
 }
 
-func buildHistogram(p *plot.Plot, fill color.NRGBA, limit uint64, fn func(n uint64) (uint64, uint64, uint64), title string) {
+func buildHistogram(p *plot.Plot, fill color.NRGBA, limit uint64, evaluate rangeFunction, value func(r shared.Result) uint64, title string) {
 	var wg sync.WaitGroup
 	workers := uint64(runtime.GOMAXPROCS(0))
 	completed := make(chan []uint64, workers)
-	for w := uint64(1); w <= workers; w++ {
+	for w := uint64(0); w < workers; w++ {
 		wg.Add(1)
 		go (func(worker uint64, workerCount uint64, limit uint64, completed chan<- []uint64) {
 			defer wg.Done()
 			values := make([]uint64, 100_000)
-			for i := worker; i < limit; i += workerCount {
-				a, _, _ := fn(i)
-				values[a]++
+			results := make([]shared.Result, chunk)
+			for from := 1 + worker*chunk; from < limit; from += workerCount * chunk {
+				batch := results[:chunkSize(from, limit)]
+				evaluate(from, batch)
+				for _, r := range batch {
+					values[value(r)]++
+				}
 			}
 			completed <- values
 		})(w, workers, limit, completed)
@@ -177,3 +234,37 @@ func buildHistogram(p *plot.Plot, fill color.NRGBA, limit uint64, fn func(n uint
 	p.Legend.Add(fmt.Sprintf("max %s = %d", title, int(maxX)))
 	p.Add(h)
 }
+
+// sampleTime prints the mean total stopping time of uniform random n from each bucket, and the mean of the total
+// stopping time over ln(n), which the heuristic model predicts is constant as n → ∞
+func sampleTime(buckets []shared.Bucket, samples int, seed int64) {
+	names := []string{"f", "g", "h"}
+	if fn != "" {
+		names = []string{fn}
+	}
+	fns := make([]func(n *big.Int) shared.WideResult, len(names))
+	header := "n\tsamples\t"
+	for i, name := range names {
+		fns[i] = WideFunctions[name]
+		header += fmt.Sprintf("mean %[1]s\ts.e.\tmean %[1]s / ln x\ts.e.\t", Types[name].Title)
+	}
+	rng := rand.New(rand.NewSource(seed))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, header)
+	for _, bucket := range buckets {
+		log.Printf("sampling %d n from %s...", samples, bucket.Label)
+		times, logs := sampleStoppingTimes(bucket.Sample(samples, rng), fns)
+		fmt.Fprintf(w, "%s\t%d\t", bucket.Label, samples)
+		for _, t := range times {
+			mean, se := shared.MeanEstimate(t)
+			normalized := make([]float64, len(t))
+			for i := range t {
+				normalized[i] = t[i] / logs[i]
+			}
+			meanNormalized, seNormalized := shared.MeanEstimate(normalized)
+			fmt.Fprintf(w, "%.3f\t%.3f\t%.5f\t%.5f\t", mean, se, meanNormalized, seNormalized)
+		}
+		fmt.Fprintln(w)
+	}
+	w.Flush()
+}
@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"math/bits"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	treeCmd = &cobra.Command{
+		Use:   "tree",
+		Short: "Write the inverse tree of C, R or R′ from 1 as Graphviz DOT",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			inverse, ok := InverseMaps[fn]
+			if !ok {
+				return fmt.Errorf("--fn should be f, g or h")
+			}
+			info := Types[fn]
+			depth, err := cmd.Flags().GetInt("depth")
+			if err != nil {
+				return err
+			}
+			if depth < 0 {
+				return fmt.Errorf("--depth must not be negative: %d", depth)
+			}
+			bound, err := cmd.Flags().GetUint64("bound")
+			if err != nil {
+				return err
+			}
+			if bound < 1 {
+				return fmt.Errorf("--bound must be at least 1: %d", bound)
+			}
+			colorBy, err := cmd.Flags().GetString("color")
+			if err != nil {
+				return err
+			}
+			modulus, err := cmd.Flags().GetUint64("mod")
+			if err != nil {
+				return err
+			}
+			var color func(x uint64) int
+			if colorBy == "residue" {
+				if modulus < 1 || modulus > 9 {
+					return fmt.Errorf("--mod must be in the range 1..9: %d", modulus)
+				}
+				color = func(x uint64) int { return int(x%modulus) + 1 }
+			} else if colorBy == "nu2" {
+				color = func(x uint64) int {
+					v := bits.TrailingZeros64(^x) // ν2(x+1)
+					if v > 8 {
+						v = 8
+					}
+					return v + 1
+				}
+			} else if colorBy != "" {
+				return fmt.Errorf("unexpected value for --color: %s", colorBy)
+			}
+			collapse, err := cmd.Flags().GetBool("collapse-even")
+			if err != nil {
+				return err
+			}
+			if collapse && fn == "f" {
+				return fmt.Errorf("--collapse-even is only available for g (R) and h (R′)")
+			}
+			fileName, err := cmd.Flags().GetString("file")
+			if err != nil {
+				return err
+			}
+			if fileName == "" {
+				fileName = fmt.Sprintf("results/tree_%s_%d.dot", info.File, depth)
+			}
+
+			var w io.Writer = os.Stdout
+			if fileName != "-" {
+				log.Printf("writing to %s...", fileName)
+				f, err := os.Create(fileName)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				w = f
+			}
+			bw := bufio.NewWriter(w)
+			nodes := writeTree(bw, inverse.Name, depth, bound, inverse.Predecessors, color, collapse)
+			log.Printf("%d nodes reach 1 within %d steps of %s without exceeding %d", nodes, depth, inverse.Name, bound)
+			return bw.Flush()
+		},
+	}
+)
+
+func init() {
+	treeCmd.Flags().StringVar(&fn, "fn", "", "which function's map to invert: f (C), g (R), h (R′)")
+	treeCmd.Flags().Int("depth", 10, "number of levels to build")
+	treeCmd.Flags().Uint64("bound", 10_000, "largest value allowed in the tree")
+	treeCmd.Flags().String("color", "", "color nodes by: residue (x mod --mod), nu2 (ν2(x+1)). leave blank for none")
+	treeCmd.Flags().Uint64("mod", 3, "modulus used by --color residue, at most 9")
+	treeCmd.Flags().Bool("collapse-even", false, "draw the even predecessors y·2^m of each y as a single node, for g and h")
+	treeCmd.Flags().String("file", "", "path of the DOT file to write, or - for stdout. leave blank for results/tree_<fn>_<depth>.dot")
+}
+
+// writeTree writes the inverse tree in DOT format, breadth first from 1, and returns the number of nodes written. Nodes
+// are filled from the set19 color scheme when color is given.
+func writeTree(w io.Writer, name string, depth int, bound uint64, predecessors func(y, bound uint64) []uint64, color func(x uint64) int, collapse bool) int {
+	node := func(x uint64) {
+		if color != nil {
+			fmt.Fprintf(w, "  %d [fillcolor=%d];\n", x, color(x))
+		} else {
+			fmt.Fprintf(w, "  %d;\n", x)
+		}
+	}
+	fmt.Fprintf(w, "digraph %q {\n", "inverse tree of "+name)
+	fmt.Fprintf(w, "  rankdir=BT;\n")
+	if color != nil {
+		fmt.Fprintf(w, "  node [style=filled, colorscheme=set19];\n")
+	}
+	node(1)
+	nodes := 1
+	level := []uint64{1}
+	for t := 1; t <= depth && len(level) > 0; t++ {
+		next := make([]uint64, 0)
+		for _, y := range level {
+			evens := 0
+			for _, x := range predecessors(y, bound) {
+				if collapse && x&1 == 0 {
+					evens++
+					continue
+				}
+				node(x)
+				fmt.Fprintf(w, "  %d -> %d;\n", x, y)
+				next = append(next, x)
+				nodes++
+			}
+			// even numbers are never reached by R or R′, so they are always leaves
+			if evens > 0 {
+				fmt.Fprintf(w, "  \"%d·2^m\" [shape=box, label=\"%d·2^m\\nm = 1..%d\"];\n", y, y, evens)
+				fmt.Fprintf(w, "  \"%d·2^m\" -> %d;\n", y, y)
+				nodes += evens
+			}
+		}
+		level = next
+	}
+	fmt.Fprintf(w, "}\n")
+	return nodes
+}
@@ -0,0 +1,212 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"hash/crc64"
+	"log"
+	"math/big"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/theriault/collatz/shared"
+)
+
+var (
+	verifyCmd = &cobra.Command{
+		Use:   "verify",
+		Short: "Prove every n in [from, to) reaches 1 and write a signed-off summary with checksums per chunk",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			from, err := cmd.Flags().GetUint64("from")
+			if err != nil {
+				return err
+			}
+			to, err := cmd.Flags().GetUint64("to")
+			if err != nil {
+				return err
+			}
+			if from < 1 || to <= from {
+				return fmt.Errorf("expected 1 <= --from < --to: %d, %d", from, to)
+			}
+			chunk, err := cmd.Flags().GetUint64("chunk")
+			if err != nil {
+				return err
+			}
+			if chunk < 1 {
+				return fmt.Errorf("--chunk must be at least 1: %d", chunk)
+			}
+			bits, err := cmd.Flags().GetUint("sieve-bits")
+			if err != nil {
+				return err
+			}
+			if bits > 28 {
+				return fmt.Errorf("--sieve-bits must be in the range 0..28: %d", bits)
+			}
+			signer, err := cmd.Flags().GetString("signer")
+			if err != nil {
+				return err
+			}
+			if strings.TrimSpace(signer) == "" {
+				signer = os.Getenv("USER")
+			}
+			fileName, err := cmd.Flags().GetString("file")
+			if err != nil {
+				return err
+			}
+			if fileName == "" {
+				fileName = fmt.Sprintf("results/verify_%d_%d.txt", from, to)
+			}
+
+			method := "descent below n under C, wide arithmetic on overflow"
+			var sieve *shared.Sieve
+			if bits > 0 {
+				log.Printf("building sieve mod 2^%d...", bits)
+				sieve = shared.NewSieve(bits)
+				method = fmt.Sprintf("descent below n under C, residue sieve mod 2^%d, wide arithmetic on overflow", bits)
+			}
+			log.Printf("verifying [%d, %d) in chunks of %d...", from, to, chunk)
+			start := time.Now()
+			chunks := verifyRange(from, to, chunk, sieve)
+			log.Printf("verified %d numbers in %s", to-from, time.Since(start))
+
+			summary := &bytes.Buffer{}
+			fmt.Fprintf(summary, "collatz verify\n")
+			fmt.Fprintf(summary, "range: [%d, %d)\n", from, to)
+			fmt.Fprintf(summary, "method: %s\n", method)
+			fmt.Fprintf(summary, "sieve-bits: %d\n", bits)
+			if sieve != nil {
+				fmt.Fprintf(summary, "covers: max-descent, max-excursion and crc64 cover only the iterated n, the sieve proves the others descend\n")
+			}
+			if from > 1 {
+				fmt.Fprintf(summary, "assumes: every n < %d reaches 1\n", from)
+			}
+			total := verifiedChunk{from: from, to: to, peak: new(big.Int)}
+			for _, c := range chunks {
+				fmt.Fprintf(summary, "chunk [%d, %d) %s crc64=%016x\n", c.from, c.to, c, c.checksum)
+				total.merge(c)
+			}
+			fmt.Fprintf(summary, "total [%d, %d) %s\n", total.from, total.to, total)
+			digest := sha256.Sum256(summary.Bytes())
+			fmt.Fprintf(summary, "sha256: %x\n", digest)
+			if strings.TrimSpace(signer) != "" {
+				fmt.Fprintf(summary, "signed-off-by: %s %s\n", signer, time.Now().UTC().Format(time.RFC3339))
+			}
+
+			fmt.Print(summary.String())
+			log.Printf("writing to %s...", fileName)
+			return os.WriteFile(fileName, summary.Bytes(), 0o644)
+		},
+	}
+)
+
+func init() {
+	verifyCmd.Flags().Uint64("from", 1, "first n to verify")
+	verifyCmd.Flags().Uint64("to", 1_000_000, "verify n up to but not including this value")
+	verifyCmd.Flags().Uint64("chunk", 1<<20, "number of n per chunk in the summary")
+	verifyCmd.Flags().Uint("sieve-bits", 16, "skip residue classes mod 2^bits that provably descend. use 0 to iterate every n")
+	verifyCmd.Flags().String("signer", "", "name to sign the summary with. leave blank for $USER")
+	verifyCmd.Flags().String("file", "", "path of the summary to write. leave blank for results/verify_<from>_<to>.txt")
+}
+
+// verifiedChunk summarizes the verification of every n in [from, to)
+type verifiedChunk struct {
+	from, to     uint64
+	iterated     uint64 // n that were not skipped by the sieve
+	wide         uint64 // n whose trajectory overflowed uint64
+	sieveSkipped uint64 // n that the sieve proves descend
+	maxStepsN    uint64
+	maxSteps     uint64
+	peakN        uint64
+	peak         *big.Int
+	checksum     uint64
+}
+
+func (c verifiedChunk) String() string {
+	return fmt.Sprintf("iterated=%d sieved=%d wide=%d max-descent=%d@%d max-excursion=%s@%d",
+		c.iterated, c.sieveSkipped, c.wide, c.maxSteps, c.maxStepsN, c.peak, c.peakN)
+}
+
+func (c *verifiedChunk) merge(other verifiedChunk) {
+	c.iterated += other.iterated
+	c.wide += other.wide
+	c.sieveSkipped += other.sieveSkipped
+	if other.maxSteps > c.maxSteps {
+		c.maxSteps, c.maxStepsN = other.maxSteps, other.maxStepsN
+	}
+	if other.peak.Cmp(c.peak) > 0 {
+		c.peak, c.peakN = other.peak, other.peakN
+	}
+}
+
+// verifyRange shows every n in [from, to) drops below itself, which proves it reaches 1 as long as every smaller n
+// does, and summarizes each chunk of the range.
+func verifyRange(from uint64, to uint64, size uint64, sieve *shared.Sieve) []verifiedChunk {
+	chunks := make([]verifiedChunk, (to-from+size-1)/size)
+	var wg sync.WaitGroup
+	workers := runtime.GOMAXPROCS(0)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go (func(worker int, workerCount int) {
+			defer wg.Done()
+			for c := worker; c < len(chunks); c += workerCount {
+				lo := from + uint64(c)*size
+				hi := lo + size
+				if hi > to || hi < lo {
+					hi = to
+				}
+				chunks[c] = verifyChunk(lo, hi, sieve)
+			}
+		})(w, workers)
+	}
+	wg.Wait()
+	return chunks
+}
+
+func verifyChunk(from uint64, to uint64, sieve *shared.Sieve) verifiedChunk {
+	c := verifiedChunk{from: from, to: to}
+	crc := crc64.New(crc64.MakeTable(crc64.ECMA))
+	record := make([]byte, 16)
+	peak := uint64(0)
+	// a trajectory that overflows uint64 always peaks above every trajectory that does not
+	var widePeak *big.Int
+	widePeakN := uint64(0)
+	for n := from; n < to; n++ {
+		if n == 1 {
+			// 1 is where every trajectory ends
+		} else if sieve != nil && sieve.Descends(n) {
+			c.sieveSkipped++
+		} else {
+			c.iterated++
+			steps, ok, maxX := shared.DescentTime(n)
+			if ok && maxX > peak {
+				peak, c.peakN = maxX, n
+			}
+			if !ok {
+				c.wide++
+				var maxWide *big.Int
+				steps, maxWide = shared.DescentTimeWide(n)
+				if widePeak == nil || maxWide.Cmp(widePeak) > 0 {
+					widePeak, widePeakN = maxWide, n
+				}
+			}
+			if steps > c.maxSteps {
+				c.maxSteps, c.maxStepsN = steps, n
+			}
+			binary.LittleEndian.PutUint64(record[:8], n)
+			binary.LittleEndian.PutUint64(record[8:], steps)
+			crc.Write(record)
+		}
+	}
+	c.checksum = crc.Sum64()
+	c.peak = new(big.Int).SetUint64(peak)
+	if widePeak != nil {
+		c.peak, c.peakN = widePeak, widePeakN
+	}
+	return c
+}
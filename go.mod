@@ -2,7 +2,10 @@ module github.com/theriault/collatz
 
 go 1.20
 
-require gonum.org/v1/plot v0.13.0
+require (
+	github.com/spf13/cobra v1.7.0
+	gonum.org/v1/plot v0.13.0
+)
 
 require (
 	git.sr.ht/~sbinet/gg v0.4.1 // indirect
@@ -12,7 +15,6 @@ require (
 	github.com/go-pdf/fpdf v0.8.0 // indirect
 	github.com/golang/freetype v0.0.0-20170609003504-e2365dfdc4a0 // indirect
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
-	github.com/spf13/cobra v1.7.0 // indirect
 	github.com/spf13/pflag v1.0.5 // indirect
 	golang.org/x/image v0.7.0 // indirect
 	golang.org/x/text v0.9.0 // indirect
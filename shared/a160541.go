@@ -0,0 +1,41 @@
+package shared
+
+// A160541 returns the number of maximal runs of halving steps in the trajectory of n under the Terras map
+//
+// T(x) = { x/2           if x ≡ 0 (mod 2)
+// .... = { (3x+1)/2      if x ≡ 1 (mod 2)
+//
+// The parity vector of a trajectory of T alternates between runs of odd steps and runs of halving steps, and always
+// ends with a halving step from 2 to 1, so the count is the number of times the trajectory moves from an even value to
+// an odd value. It is computed step by step and does not refer to R′, which makes it an independent check of the
+// conjecture that h gives A160541.
+//
+// https://oeis.org/A160541
+func A160541(n uint64) uint64 {
+	runs := uint64(0)
+	for n != 1 {
+		if n&1 == 1 {
+			n = n + n>>1 + 1 // (3n+1)/2
+		} else {
+			n >>= 1 // n/2
+			if n&1 == 1 {
+				runs++
+			}
+		}
+	}
+	return runs
+}
+
+// Trajectory returns the values x, C(x), C(C(x)), ... of the standard Collatz map from n down to 1
+func Trajectory(n uint64) []uint64 {
+	trajectory := []uint64{n}
+	for n != 1 {
+		if n&1 == 1 {
+			n = n<<1 + n + 1 // 3n+1
+		} else {
+			n >>= 1 // n/2
+		}
+		trajectory = append(trajectory, n)
+	}
+	return trajectory
+}
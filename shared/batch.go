@@ -0,0 +1,181 @@
+package shared
+
+import (
+	"fmt"
+)
+
+// MaxBatchCache is the largest number of results a Batch can cache, which take 1 GiB at 16 bytes each. Orbits of n
+// below 2^32 take fewer than 2^16 steps, so the cached counts fit in a uint16.
+const MaxBatchCache = 1 << 26
+
+// Batch evaluates f, g or h for many n, stopping each orbit as soon as it drops below n into a cache of already known
+// results for small values, rather than following it all the way to 1.
+//
+// The cache holds the results for 1 ≤ n < its size, and is filled when the Batch is created. Each n in the cache only
+// needs its orbit until it first drops below n, which is its stopping time rather than its total stopping time, so
+// this is much cheaper than evaluating each n on its own.
+type Batch struct {
+	step  batchStep
+	cache []cachedResult
+}
+
+// batchStep takes a step of the map of f, g or h from x, returning the next value, the number of odd steps that are
+// each followed by an even step, the number of other odd steps, the number of even steps that follow them, and false
+// if the step overflows
+type batchStep func(x uint64) (next uint64, pairs uint64, odd uint64, even uint64, ok bool)
+
+// cachedResult is a Result without the even steps, which follow from the others, and overflow, which is impossible
+// below MaxBatchCache
+type cachedResult struct {
+	time            uint16
+	standardTime    uint16
+	odd             uint16
+	coefficientTime uint16
+	max             uint64
+}
+
+// NewBatchF returns a Batch for CollatzStoppingTimeF caching the results of 1 ≤ n < size
+func NewBatchF(size uint64) *Batch {
+	return newBatch(size, func(x uint64) (uint64, uint64, uint64, uint64, bool) {
+		if x&1 == 0 {
+			return x >> 1, 0, 0, 1, true // x/2
+		}
+		if x > maxOdd {
+			return 0, 0, 0, 0, false
+		}
+		return x<<1 + x + 1, 0, 1, 0, true // 3x+1
+	})
+}
+
+// NewBatchG returns a Batch for CollatzStoppingTimeG caching the results of 1 ≤ n < size
+func NewBatchG(size uint64) *Batch {
+	return newBatch(size, func(x uint64) (uint64, uint64, uint64, uint64, bool) {
+		if x&1 == 0 {
+			x, m := divideOut(x)
+			return x, 0, 0, m, true
+		}
+		if x > maxOdd {
+			return 0, 0, 0, 0, false
+		}
+		x, steps := R(x)
+		return x, 0, 1, steps - 1, true
+	})
+}
+
+// NewBatchH returns a Batch for CollatzStoppingTimeH caching the results of 1 ≤ n < size
+func NewBatchH(size uint64) *Batch {
+	return newBatch(size, func(x uint64) (uint64, uint64, uint64, uint64, bool) {
+		if x&1 == 0 {
+			x, m := divideOut(x)
+			return x, 0, 0, m, true
+		}
+		x, v, m, ok := RPrime(x)
+		return x, v, 0, m, ok
+	})
+}
+
+func newBatch(size uint64, step batchStep) *Batch {
+	if size < 2 || size > MaxBatchCache {
+		panic(fmt.Sprintf("batch cache size must be in the range 2..%d: %d", uint64(MaxBatchCache), size))
+	}
+	b := &Batch{step: step, cache: make([]cachedResult, 1, size)}
+	// every orbit drops below n before reaching 1, so evaluating in increasing order always finds the rest in the cache
+	for n := uint64(1); n < size; n++ {
+		r := b.Evaluate(n)
+		b.cache = append(b.cache, cachedResult{
+			time:            uint16(r.Time),
+			standardTime:    uint16(r.StandardTime),
+			odd:             uint16(r.Odd),
+			coefficientTime: uint16(r.CoefficientTime),
+			max:             r.Max,
+		})
+	}
+	return b
+}
+
+// CacheSize returns the number of results cached, including the unused entry for 0
+func (b *Batch) CacheSize() uint64 {
+	return uint64(len(b.cache))
+}
+
+// Evaluate returns the same Result as the function of the Batch. It is safe for concurrent use.
+func (b *Batch) Evaluate(n uint64) Result {
+	return b.evaluate(n, n, nil)
+}
+
+// evaluate returns the Result of n, stopping its orbit once it drops below n into the cache, or into [from, n) whose
+// results are in known. An orbit that drops below from but not into the cache is followed further.
+func (b *Batch) evaluate(n uint64, from uint64, known []Result) Result {
+	if n > 0 && n < uint64(len(b.cache)) {
+		return b.cached(n, Result{})
+	}
+	r := Result{Max: n}
+	x := n
+	for x != 1 {
+		// the coefficient stopping time is always reached by the time the orbit drops below n, so it is never needed
+		// from the cache or the range
+		if x < n {
+			if x < uint64(len(b.cache)) {
+				return b.cached(x, r)
+			}
+			if x >= from {
+				return followedBy(r, known[x-from])
+			}
+		}
+		next, pairs, odd, even, ok := b.step(x)
+		if !ok {
+			r.Overflow = true
+			return r
+		}
+		r.Odd += pairs + odd
+		r.Even += pairs
+		r.countEvens(even)
+		r.StandardTime += 2*pairs + odd + even
+		r.Time++
+		x = next
+		if x > r.Max {
+			r.Max = x
+		}
+	}
+	return r
+}
+
+// cached returns r followed by the cached result of x
+func (b *Batch) cached(x uint64, r Result) Result {
+	c := b.cache[x]
+	r.Time += uint64(c.time)
+	r.StandardTime += uint64(c.standardTime)
+	r.Odd += uint64(c.odd)
+	r.Even += uint64(c.standardTime - c.odd)
+	if r.CoefficientTime == 0 {
+		r.CoefficientTime = uint64(c.coefficientTime)
+	}
+	if c.max > r.Max {
+		r.Max = c.max
+	}
+	return r
+}
+
+// followedBy returns r followed by the result of the value its orbit reached
+func followedBy(r Result, next Result) Result {
+	r.Time += next.Time
+	r.StandardTime += next.StandardTime
+	r.Odd += next.Odd
+	r.Even += next.Even
+	if r.CoefficientTime == 0 {
+		r.CoefficientTime = next.CoefficientTime
+	}
+	if next.Max > r.Max {
+		r.Max = next.Max
+	}
+	r.Overflow = next.Overflow
+	return r
+}
+
+// EvaluateRange stores the Result of each n in [from, from+len(results)) in results. Besides the cache, each orbit
+// stops as soon as it drops below n to a value at or above from, whose Result is already in results.
+func (b *Batch) EvaluateRange(from uint64, results []Result) {
+	for i := range results {
+		results[i] = b.evaluate(from+uint64(i), from, results[:i])
+	}
+}
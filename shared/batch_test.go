@@ -0,0 +1,52 @@
+package shared
+
+import (
+	"math"
+	"testing"
+)
+
+func TestBatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		newBatch func(size uint64) *Batch
+		fn       func(n uint64) Result
+	}{
+		{name: "f", newBatch: NewBatchF, fn: CollatzStoppingTimeF},
+		{name: "g", newBatch: NewBatchG, fn: CollatzStoppingTimeG},
+		{name: "h", newBatch: NewBatchH, fn: CollatzStoppingTimeH},
+	}
+	// ranges below, across and above the cache, and ranges whose orbits overflow
+	ranges := []struct{ from, count uint64 }{
+		{from: 1, count: 1 << 12},
+		{from: 1<<10 - 100, count: 200},
+		{from: 1 << 11, count: 1 << 14},
+		{from: 1 << 20, count: 1 << 12},
+		{from: maxOdd - 1<<10, count: 1 << 11},
+		{from: math.MaxUint64 - 1<<10, count: 1 << 10},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			b := test.newBatch(1 << 10)
+			for _, rng := range ranges {
+				results := make([]Result, rng.count)
+				b.EvaluateRange(rng.from, results)
+				for i, r := range results {
+					n := rng.from + uint64(i)
+					expected := test.fn(n)
+					if expected.Overflow {
+						if !r.Overflow {
+							t.Fatalf("EvaluateRange gives %+v for %d, expected overflow", r, n)
+						}
+						continue
+					}
+					if r != expected {
+						t.Fatalf("EvaluateRange gives %+v for %d, expected %+v", r, n, expected)
+					}
+					if e := b.Evaluate(n); e != expected {
+						t.Fatalf("Evaluate gives %+v for %d, expected %+v", e, n, expected)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package shared
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Term is a single term a(n) of an integer sequence
+type Term struct {
+	N     uint64
+	Value uint64
+}
+
+// ReadBFile reads the terms of an OEIS b-file, a text file with one "n a(n)" pair per line.
+//
+// Blank lines and lines starting with # are ignored.
+//
+// https://oeis.org/SeqFormat.html
+func ReadBFile(r io.Reader) ([]Term, error) {
+	terms := make([]Term, 0)
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Fields(text)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("line %d: expected \"n a(n)\": %q", line, text)
+		}
+		n, err := strconv.ParseUint(fields[0], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		value, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		terms = append(terms, Term{N: n, Value: value})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return terms, nil
+}
+
+// WriteBFile writes the given header comments and terms in the OEIS b-file format
+func WriteBFile(w io.Writer, header []string, terms []Term) error {
+	bw := bufio.NewWriter(w)
+	for _, comment := range header {
+		if _, err := fmt.Fprintf(bw, "# %s\n", comment); err != nil {
+			return err
+		}
+	}
+	for _, term := range terms {
+		if _, err := fmt.Fprintf(bw, "%d %d\n", term.N, term.Value); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,85 @@
+package shared
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// bFileFunctions are the functions whose values are given by each sequence in testdata
+var bFileFunctions = map[string]func(n uint64) Result{
+	"b006577.txt": CollatzStoppingTimeF,
+	"b286380.txt": CollatzStoppingTimeG,
+	"b160541.txt": CollatzStoppingTimeH,
+}
+
+func TestBFiles(t *testing.T) {
+	for name, fn := range bFileFunctions {
+		t.Run(strings.TrimSuffix(name, ".txt"), func(t *testing.T) {
+			f, err := os.Open(filepath.Join("testdata", name))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			terms, err := ReadBFile(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(terms) == 0 {
+				t.Fatal("no terms")
+			}
+			for _, term := range terms {
+				if r := fn(term.N); r.Overflow || r.Time != term.Value {
+					t.Errorf("a(%d) = %d but the function gives %+v", term.N, term.Value, r)
+				}
+			}
+		})
+	}
+}
+
+func TestReadBFile(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		terms []Term
+		err   bool
+	}{
+		{name: "empty", input: "", terms: []Term{}},
+		{name: "comments and blank lines", input: "# A006577\n\n1 0\n  2 1  \n# end\n", terms: []Term{{1, 0}, {2, 1}}},
+		{name: "missing value", input: "1\n", err: true},
+		{name: "extra field", input: "1 0 0\n", err: true},
+		{name: "negative", input: "1 -1\n", err: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			terms, err := ReadBFile(strings.NewReader(test.input))
+			if (err != nil) != test.err {
+				t.Fatalf("error = %v, expected error %t", err, test.err)
+			}
+			if !test.err && !reflect.DeepEqual(terms, test.terms) {
+				t.Fatalf("terms = %v, expected %v", terms, test.terms)
+			}
+		})
+	}
+}
+
+func TestWriteBFile(t *testing.T) {
+	terms := []Term{{1, 0}, {2, 1}, {3, 7}}
+	var b bytes.Buffer
+	if err := WriteBFile(&b, []string{"A006577"}, terms); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "# A006577\n1 0\n2 1\n3 7\n"; b.String() != expected {
+		t.Fatalf("wrote %q, expected %q", b.String(), expected)
+	}
+	read, err := ReadBFile(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(read, terms) {
+		t.Fatalf("read back %v, expected %v", read, terms)
+	}
+}
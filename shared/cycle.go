@@ -0,0 +1,118 @@
+package shared
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// StepFunc returns the value after x, or false if it does not fit in a uint64
+type StepFunc func(x uint64) (uint64, bool)
+
+// Cycle is a cycle of a map, identified by its smallest element
+type Cycle struct {
+	Min    uint64
+	Length uint64
+	Max    uint64
+}
+
+// GeneralizedStep returns the step of the generalized Collatz map
+//
+// C_q,r(x) = { x/2       if x ≡ 0 (mod 2)
+// ........ = { qx+r      if x ≡ 1 (mod 2)
+//
+// Where q and r are odd, so C_3,1 is C.
+func GeneralizedStep(q, r uint64) StepFunc {
+	if q&1 == 0 || r&1 == 0 {
+		panic(fmt.Sprintf("q and r must be odd: %d, %d", q, r))
+	}
+	return func(x uint64) (uint64, bool) {
+		if x&1 == 0 {
+			return x >> 1, true
+		}
+		hi, lo := bits.Mul64(x, q)
+		lo, carry := bits.Add64(lo, r, 0)
+		return lo, hi == 0 && carry == 0
+	}
+}
+
+// MapStep returns the step of m as a StepFunc, without the number of standard steps it jumps ahead
+func MapStep(m Map) StepFunc {
+	return func(x uint64) (uint64, bool) {
+		x, _, ok := m.Step(x)
+		return x, ok
+	}
+}
+
+// CycleStop is the reason FindCycle stopped following a trajectory
+type CycleStop int
+
+const (
+	// StopBelow is a trajectory that dropped below its start
+	StopBelow CycleStop = iota
+	// StopCycle is a trajectory that entered a cycle
+	StopCycle
+	// StopOverflow is a trajectory that left the range of uint64
+	StopOverflow
+	// StopMaxSteps is a trajectory that took more than the maximum number of steps
+	StopMaxSteps
+)
+
+func (s CycleStop) String() string {
+	switch s {
+	case StopBelow:
+		return "below start"
+	case StopCycle:
+		return "cycle"
+	case StopOverflow:
+		return "overflow"
+	case StopMaxSteps:
+		return "max steps"
+	}
+	return fmt.Sprintf("CycleStop(%d)", int(s))
+}
+
+// FindCycle follows the trajectory of n with step using Brent's algorithm, and returns why it stopped. It stops as soon
+// as the trajectory drops below n, since the fate of smaller values is already known when they are examined in
+// increasing order, or when the trajectory overflows or takes more than maxSteps steps.
+//
+// Otherwise it returns the cycle the trajectory enters, whose smallest element is at least n, and StopCycle.
+func FindCycle(n uint64, step StepFunc, maxSteps uint64) (Cycle, CycleStop) {
+	// Brent's algorithm: the tortoise teleports to the hare whenever the hare has taken a power of 2 steps
+	power, lambda := uint64(1), uint64(1)
+	tortoise := n
+	hare, ok := step(n)
+	for steps := uint64(1); ; steps++ {
+		if !ok {
+			return Cycle{}, StopOverflow
+		}
+		if hare < n {
+			return Cycle{}, StopBelow
+		}
+		if steps > maxSteps {
+			return Cycle{}, StopMaxSteps
+		}
+		if tortoise == hare {
+			break
+		}
+		if power == lambda {
+			tortoise = hare
+			power <<= 1
+			lambda = 0
+		}
+		hare, ok = step(hare)
+		lambda++
+	}
+	// walk the cycle once to find its smallest and largest elements
+	cycle := Cycle{Min: hare, Length: lambda, Max: hare}
+	x := hare
+	for i := uint64(1); i < lambda; i++ {
+		x, _ = step(x)
+		if x < cycle.Min {
+			cycle.Min = x
+		}
+		if x > cycle.Max {
+			cycle.Max = x
+		}
+	}
+	return cycle, StopCycle
+}
@@ -0,0 +1,36 @@
+package shared
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFindCycle(t *testing.T) {
+	tests := []struct {
+		name     string
+		n        uint64
+		step     StepFunc
+		maxSteps uint64
+		stop     CycleStop
+		cycle    Cycle
+	}{
+		{name: "C through 1", n: 1, step: GeneralizedStep(3, 1), maxSteps: 100, stop: StopCycle, cycle: Cycle{Min: 1, Length: 3, Max: 4}},
+		{name: "R through 1", n: 1, step: MapStep(MapR), maxSteps: 100, stop: StopCycle, cycle: Cycle{Min: 1, Length: 1, Max: 1}},
+		{name: "R′ through 1", n: 1, step: MapStep(MapRPrime), maxSteps: 100, stop: StopCycle, cycle: Cycle{Min: 1, Length: 1, Max: 1}},
+		{name: "5x+1 through 13", n: 13, step: GeneralizedStep(5, 1), maxSteps: 100, stop: StopCycle, cycle: Cycle{Min: 13, Length: 10, Max: 416}},
+		{name: "C below start", n: 27, step: GeneralizedStep(3, 1), maxSteps: 1000, stop: StopBelow},
+		{name: "R′ below start", n: 27, step: MapStep(MapRPrime), maxSteps: 1000, stop: StopBelow},
+		{name: "C overflow", n: math.MaxUint64, step: GeneralizedStep(3, 1), maxSteps: 1000, stop: StopOverflow},
+		{name: "R overflow", n: math.MaxUint64, step: MapStep(MapR), maxSteps: 1000, stop: StopOverflow},
+		{name: "R′ overflow", n: math.MaxUint64, step: MapStep(MapRPrime), maxSteps: 1000, stop: StopOverflow},
+		{name: "max steps", n: 27, step: GeneralizedStep(3, 1), maxSteps: 10, stop: StopMaxSteps},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cycle, stop := FindCycle(test.n, test.step, test.maxSteps)
+			if stop != test.stop || cycle != test.cycle {
+				t.Fatalf("FindCycle(%d) = %+v, %s, expected %+v, %s", test.n, cycle, stop, test.cycle, test.stop)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package shared
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// InverseLogFit is the result of fitting a sequence to the model y = L + a/ln(x), along with confidence intervals
+// for both parameters.
+type InverseLogFit struct {
+	Limit      float64
+	Slope      float64
+	LimitLow   float64
+	LimitHigh  float64
+	SlopeLow   float64
+	SlopeHigh  float64
+	Confidence float64
+}
+
+// At evaluates the fitted model at x
+func (f InverseLogFit) At(x float64) float64 {
+	return f.Limit + f.Slope/math.Log(x)
+}
+
+// FitInverseLog returns the ordinary least squares estimates of L and a for the model y = L + a/ln(x).
+//
+// The model is linear in z = 1/ln(x), so this is a simple linear regression of y on z. It returns an error if any x is
+// not greater than 1, where 1/ln(x) is infinite or negative.
+func FitInverseLog(xs, ys []float64) (limit float64, slope float64, err error) {
+	n := float64(len(xs))
+	sumZ, sumY, sumZZ, sumZY := 0.0, 0.0, 0.0, 0.0
+	for i := range xs {
+		if !(xs[i] > 1) {
+			return 0, 0, fmt.Errorf("x must be greater than 1 to fit L + a/ln(x): %g", xs[i])
+		}
+		z := 1 / math.Log(xs[i])
+		sumZ += z
+		sumY += ys[i]
+		sumZZ += z * z
+		sumZY += z * ys[i]
+	}
+	slope = (n*sumZY - sumZ*sumY) / (n*sumZZ - sumZ*sumZ)
+	limit = (sumY - slope*sumZ) / n
+	return limit, slope, nil
+}
+
+// BootstrapInverseLog fits y = L + a/ln(x) and estimates confidence intervals for L and a using a moving block
+// bootstrap of the residuals.
+//
+// Consecutive points of a cumulative ratio are strongly correlated, so residuals are resampled in contiguous blocks of
+// the given length rather than individually. A block length of 0 uses √len(xs). Like FitInverseLog, it returns an error
+// if any x is not greater than 1.
+func BootstrapInverseLog(xs, ys []float64, samples int, block int, confidence float64, rng *rand.Rand) (InverseLogFit, error) {
+	limit, slope, err := FitInverseLog(xs, ys)
+	if err != nil {
+		return InverseLogFit{}, err
+	}
+	fit := InverseLogFit{
+		Limit:      limit,
+		Slope:      slope,
+		LimitLow:   limit,
+		LimitHigh:  limit,
+		SlopeLow:   slope,
+		SlopeHigh:  slope,
+		Confidence: confidence,
+	}
+	n := len(xs)
+	if samples < 1 || n < 3 {
+		return fit, nil
+	}
+	if block < 1 {
+		block = int(math.Sqrt(float64(n)))
+	}
+	if block > n {
+		block = n
+	}
+
+	residuals := make([]float64, n)
+	for i := range xs {
+		residuals[i] = ys[i] - fit.At(xs[i])
+	}
+	limits := make([]float64, samples)
+	slopes := make([]float64, samples)
+	resampled := make([]float64, n)
+	for s := 0; s < samples; s++ {
+		for i := 0; i < n; i += block {
+			start := rng.Intn(n - block + 1)
+			for j := 0; j < block && i+j < n; j++ {
+				resampled[i+j] = fit.At(xs[i+j]) + residuals[start+j]
+			}
+		}
+		// the xs were checked by the first fit
+		limits[s], slopes[s], _ = FitInverseLog(xs, resampled)
+	}
+	fit.LimitLow, fit.LimitHigh = percentileInterval(limits, confidence)
+	fit.SlopeLow, fit.SlopeHigh = percentileInterval(slopes, confidence)
+	return fit, nil
+}
+
+// percentileInterval returns the central interval of the given values that covers the given confidence
+func percentileInterval(values []float64, confidence float64) (float64, float64) {
+	sort.Float64s(values)
+	tail := (1 - confidence) / 2
+	low := int(math.Floor(tail * float64(len(values)-1)))
+	high := int(math.Ceil((1 - tail) * float64(len(values)-1)))
+	return values[low], values[high]
+}
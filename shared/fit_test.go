@@ -0,0 +1,43 @@
+package shared
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestFitInverseLog(t *testing.T) {
+	xs := make([]float64, 100)
+	ys := make([]float64, len(xs))
+	for i := range xs {
+		xs[i] = float64(1000 * (i + 1))
+		ys[i] = 1.0/6 + 0.5/math.Log(xs[i])
+	}
+	limit, slope, err := FitInverseLog(xs, ys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(limit-1.0/6) > 1e-9 || math.Abs(slope-0.5) > 1e-9 {
+		t.Fatalf("fit L = %g, a = %g, expected 1/6 and 0.5", limit, slope)
+	}
+	fit, err := BootstrapInverseLog(xs, ys, 100, 0, 0.95, rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fit.LimitLow > 1.0/6+1e-9 || fit.LimitHigh < 1.0/6-1e-9 {
+		t.Fatalf("confidence interval %g..%g does not contain 1/6", fit.LimitLow, fit.LimitHigh)
+	}
+}
+
+func TestFitInverseLogDomain(t *testing.T) {
+	for _, x := range []float64{1, 0.5, 0, -1, math.NaN()} {
+		xs := []float64{x, 10, 100}
+		ys := []float64{1, 1, 1}
+		if _, _, err := FitInverseLog(xs, ys); err == nil {
+			t.Errorf("FitInverseLog accepts x = %g", x)
+		}
+		if _, err := BootstrapInverseLog(xs, ys, 10, 0, 0.95, rand.New(rand.NewSource(1))); err == nil {
+			t.Errorf("BootstrapInverseLog accepts x = %g", x)
+		}
+	}
+}
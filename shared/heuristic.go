@@ -0,0 +1,107 @@
+package shared
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// AbsorbingChain is a Markov chain over transient states, each of which earns a reward when visited. Any probability
+// missing from a row of Transitions is the probability of being absorbed from that state.
+type AbsorbingChain struct {
+	States      []string
+	Transitions [][]*big.Rat
+	Rewards     []*big.Rat
+}
+
+// ExpectedReward returns the expected total reward earned from the given state until the chain is absorbed.
+//
+// The expected rewards t satisfy t = r + Qt, so this solves (I - Q)t = r exactly by Gauss-Jordan elimination.
+func (c AbsorbingChain) ExpectedReward(start int) (*big.Rat, error) {
+	n := len(c.States)
+	// augmented matrix [I - Q | r]
+	a := make([][]*big.Rat, n)
+	for i := range a {
+		a[i] = make([]*big.Rat, n+1)
+		for j := 0; j < n; j++ {
+			a[i][j] = new(big.Rat).Neg(c.Transitions[i][j])
+			if i == j {
+				a[i][j].Add(a[i][j], big.NewRat(1, 1))
+			}
+		}
+		a[i][n] = new(big.Rat).Set(c.Rewards[i])
+	}
+	product := new(big.Rat)
+	for col := 0; col < n; col++ {
+		pivot := -1
+		for row := col; row < n; row++ {
+			if a[row][col].Sign() != 0 {
+				pivot = row
+				break
+			}
+		}
+		if pivot < 0 {
+			return nil, fmt.Errorf("the chain is never absorbed from %s", c.States[col])
+		}
+		a[col], a[pivot] = a[pivot], a[col]
+		inverse := new(big.Rat).Inv(a[col][col])
+		for j := col; j <= n; j++ {
+			a[col][j].Mul(a[col][j], inverse)
+		}
+		for row := 0; row < n; row++ {
+			if row == col || a[row][col].Sign() == 0 {
+				continue
+			}
+			factor := new(big.Rat).Set(a[row][col])
+			for j := col; j <= n; j++ {
+				a[row][j].Sub(a[row][j], product.Mul(factor, a[col][j]))
+			}
+		}
+	}
+	return a[start][n], nil
+}
+
+// half is the probability of a random even number being divisible by 4, and of a random odd x having x+1 divisible
+// by 4
+var half = big.NewRat(1, 2)
+
+// RStepChain models a step of R from a random odd x under the heuristic that every new division by 2 leaves an even
+// number with probability 1/2. The reward is the number of standard steps.
+var RStepChain = AbsorbingChain{
+	States: []string{"3x+1", "x/2"},
+	Transitions: [][]*big.Rat{
+		{big.NewRat(0, 1), big.NewRat(1, 1)},
+		{big.NewRat(0, 1), half},
+	},
+	Rewards: []*big.Rat{big.NewRat(1, 1), big.NewRat(1, 1)},
+}
+
+// RPrimeStepChain models a step of R′ from a random odd x under the same heuristic: each (3x+1)/2 leaves x+1
+// divisible by 2 again with probability 1/2, and each division by 2 leaves an even number with probability 1/2. The
+// reward is the number of standard steps, 2 for each (3x+1)/2.
+var RPrimeStepChain = AbsorbingChain{
+	States: []string{"(3x+1)/2", "x/2"},
+	Transitions: [][]*big.Rat{
+		{half, half},
+		{big.NewRat(0, 1), half},
+	},
+	Rewards: []*big.Rat{big.NewRat(2, 1), big.NewRat(1, 1)},
+}
+
+// PredictedRatios returns the limits of Σh(x)/Σf(x) and Σh(x)/Σg(x) predicted by the heuristic model, along with the
+// expected standard steps per step of R and R′ they are derived from.
+//
+// f counts standard steps, g counts R steps and h counts R′ steps, so in the long run Σh/Σf is the reciprocal of the
+// standard steps per R′ step, and Σh/Σg is the standard steps per R step over the standard steps per R′ step.
+func PredictedRatios() (hf *big.Rat, hg *big.Rat, perR *big.Rat, perRPrime *big.Rat) {
+	perR, err := RStepChain.ExpectedReward(0)
+	if err != nil {
+		panic(err)
+	}
+	perRPrime, err = RPrimeStepChain.ExpectedReward(0)
+	if err != nil {
+		panic(err)
+	}
+	hf = new(big.Rat).Inv(perRPrime)
+	hg = new(big.Rat).Quo(perR, perRPrime)
+	return hf, hg, perR, perRPrime
+}
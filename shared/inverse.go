@@ -0,0 +1,193 @@
+package shared
+
+import (
+	"math"
+	"math/bits"
+)
+
+// InverseMap enumerates predecessors under one of C, R or R′, so the tree of every number that reaches 1 can be built
+// backwards from 1.
+type InverseMap struct {
+	Name string
+	// Predecessors returns every x ≤ bound, other than 1, that the map takes to y in a single step
+	Predecessors func(y uint64, bound uint64) []uint64
+	// Bound returns the largest y that can have a predecessor ≤ n exactly steps ≥ 1 steps back, or math.MaxUint64
+	// when it does not fit
+	Bound func(n uint64, steps int) uint64
+}
+
+// InverseC inverts C: every y has the predecessor 2y, and (y-1)/3 when y ≡ 4 (mod 6)
+var InverseC = InverseMap{
+	Name:         "C",
+	Predecessors: PredecessorsC,
+	// consecutive predecessors of the form (y-1)/3 are impossible since (y-1)/3 is odd, so every two steps back
+	// shrink y by at most 2/3, and y·(2/3)^⌊s/2⌋·(1/3)^(s mod 2) - 2 is a lower bound after s steps
+	Bound: func(n uint64, steps int) uint64 {
+		return saturate((float64(n) + 2) * math.Pow(1.5, float64(steps/2)) * math.Pow(3, float64(steps%2)))
+	},
+}
+
+// InverseR inverts the step of g: an odd y has the predecessors y·2^m and (y·2^m - 1)/3 for each m ≥ 1 where it is an
+// integer, while even numbers are never reached.
+var InverseR = InverseMap{
+	Name:         "R",
+	Predecessors: PredecessorsR,
+	// each odd predecessor (y·2^m - 1)/3 is at least (2y - 1)/3, and even predecessors have no predecessors, so
+	// y·(2/3)^s - 1 is a lower bound after s steps
+	Bound: func(n uint64, steps int) uint64 {
+		return saturate((float64(n) + 1) * math.Pow(1.5, float64(steps)))
+	},
+}
+
+// InverseRPrime inverts the step of h: an odd y has the predecessors y·2^m and 2^v (y·2^m + 1)/3^v - 1 for each
+// m, v ≥ 1 where it is an integer, while even numbers are never reached.
+var InverseRPrime = InverseMap{
+	Name:         "R′",
+	Predecessors: PredecessorsRPrime,
+	// an odd x with x + 1 = 2^v·u reaches y = (3^v·u - 1)/2^m ≤ ((3/2)^v (x + 1) - 1)/2 with m ≥ 1, and 2^v ≤ x + 1,
+	// so y ≤ ((x + 1)^log2(3) - 1)/2
+	Bound: func(n uint64, steps int) uint64 {
+		bound := float64(n)
+		for i := 0; i < steps && bound < math.MaxUint64; i++ {
+			bound = (math.Pow(bound+1, math.Log2(3)) - 1) / 2
+		}
+		return saturate(bound)
+	},
+}
+
+// saturate converts x to a uint64, rounding down, or returns math.MaxUint64 when it does not fit
+func saturate(x float64) uint64 {
+	if x >= math.MaxUint64 {
+		return math.MaxUint64
+	}
+	return uint64(x)
+}
+
+// PredecessorsC returns every x ≤ bound, other than 1, with C(x) = y
+func PredecessorsC(y uint64, bound uint64) []uint64 {
+	predecessors := make([]uint64, 0, 2)
+	if y <= bound>>1 {
+		predecessors = append(predecessors, y<<1)
+	}
+	// (y-1)/3 is odd exactly when y ≡ 4 (mod 6)
+	if y%6 == 4 && (y-1)/3 > 1 && (y-1)/3 <= bound {
+		predecessors = append(predecessors, (y-1)/3)
+	}
+	return predecessors
+}
+
+// PredecessorsR returns every x ≤ bound, other than 1, where x/2^m = y for even x, or R(x) = y for odd x
+func PredecessorsR(y uint64, bound uint64) []uint64 {
+	predecessors := make([]uint64, 0)
+	if y&1 == 0 {
+		return predecessors
+	}
+	for m := 1; m < 64; m++ {
+		hi, shifted := bits.Mul64(y, 1<<m)
+		if hi != 0 || (shifted-1)/3 > bound {
+			break
+		}
+		if shifted <= bound {
+			predecessors = append(predecessors, shifted)
+		}
+		// 3x+1 = y·2^m, and x is odd since y·2^m - 1 is
+		if shifted%3 == 1 && (shifted-1)/3 > 1 {
+			predecessors = append(predecessors, (shifted-1)/3)
+		}
+	}
+	return predecessors
+}
+
+// PredecessorsRPrime returns every x ≤ bound, other than 1, where x/2^m = y for even x, or R′(x) = y for odd x
+func PredecessorsRPrime(y uint64, bound uint64) []uint64 {
+	predecessors := make([]uint64, 0)
+	if y&1 == 0 {
+		return predecessors
+	}
+	// smallest is 2^k for the largest power 3^k ≤ y·2^m + 1, which only grows with m
+	smallest, power := uint64(1), uint64(1)
+	for m := 1; m < 64; m++ {
+		hi, shifted := bits.Mul64(y, 1<<m)
+		if hi != 0 || shifted == 1<<64-1 {
+			break
+		}
+		if shifted <= bound {
+			predecessors = append(predecessors, shifted)
+		}
+		// x + 1 = 2^v·u with u odd, and (3^v·u - 1)/2^m = y, so u = (y·2^m + 1)/3^v
+		numerator := shifted + 1
+		u := numerator
+		for v := uint(1); u%3 == 0; v++ {
+			u /= 3
+			if u > (1<<64-1)>>v {
+				continue
+			}
+			if x := u<<v - 1; x > 1 && x <= bound {
+				predecessors = append(predecessors, x)
+			}
+		}
+		// every predecessor has x + 1 ≥ 2^⌊log3(y·2^m + 1)⌋
+		for power <= numerator/3 && smallest <= bound {
+			power *= 3
+			smallest <<= 1
+		}
+		if smallest-1 > bound && shifted > bound {
+			break
+		}
+	}
+	return predecessors
+}
+
+// InverseTree returns the levels of the tree of numbers reaching 1 under m, where level t holds every x ≤ bound whose
+// trajectory reaches 1 in exactly t steps without exceeding the bound.
+func InverseTree(m InverseMap, depth int, bound uint64) [][]uint64 {
+	levels := [][]uint64{{1}}
+	for t := 1; t <= depth; t++ {
+		level := make([]uint64, 0)
+		for _, y := range levels[t-1] {
+			level = append(level, m.Predecessors(y, bound)...)
+		}
+		levels = append(levels, level)
+	}
+	return levels
+}
+
+// LevelSet returns every n ≤ bound whose trajectory under m reaches 1 in exactly t steps, such as every n ≤ bound with
+// h(n) = t for InverseRPrime, in no particular order.
+//
+// It walks the inverse tree from 1 and prunes every y that cannot have a predecessor ≤ bound at level t. Values along
+// the way may exceed the bound. maxValue caps them further when the largest value reached by any trajectory below the
+// bound is known, or is math.MaxUint64 otherwise. The bounds of R and R′ grow exponentially with the steps left, so
+// the walk can grow quickly for large t. It gives up and returns false once it has found more than limit values along
+// the way, when evaluating every n ≤ bound directly is cheaper.
+func LevelSet(m InverseMap, t int, bound uint64, maxValue uint64, limit int) ([]uint64, bool) {
+	if bound < 1 {
+		return nil, true
+	}
+	level := []uint64{1}
+	walked := 0
+	for d := 1; d <= t; d++ {
+		levelBound := bound
+		if d < t {
+			levelBound = m.Bound(bound, t-d)
+		}
+		if levelBound > maxValue {
+			levelBound = maxValue
+		}
+		next := make([]uint64, 0)
+		for _, y := range level {
+			for _, x := range m.Predecessors(y, levelBound) {
+				// only C reaches even numbers, so even predecessors under R and R′ are leaves
+				if d == t || x&1 == 1 || m.Name == InverseC.Name {
+					next = append(next, x)
+				}
+			}
+			if walked+len(next) > limit {
+				return nil, false
+			}
+		}
+		walked += len(next)
+		level = next
+	}
+	return level, true
+}
@@ -0,0 +1,53 @@
+package shared
+
+import (
+	"math"
+	"sort"
+	"testing"
+)
+
+func TestLevelSet(t *testing.T) {
+	const bound = 1 << 14
+	tests := []struct {
+		m  InverseMap
+		fn func(n uint64) Result
+	}{
+		{m: InverseC, fn: CollatzStoppingTimeF},
+		{m: InverseR, fn: CollatzStoppingTimeG},
+		{m: InverseRPrime, fn: CollatzStoppingTimeH},
+	}
+	for _, test := range tests {
+		t.Run(test.m.Name, func(t *testing.T) {
+			expected := make(map[uint64][]uint64)
+			maxValue := uint64(1)
+			for n := uint64(1); n <= bound; n++ {
+				r := test.fn(n)
+				expected[r.Time] = append(expected[r.Time], n)
+				if r.Max > maxValue {
+					maxValue = r.Max
+				}
+			}
+			for level := 0; level <= 12; level++ {
+				levelSet, ok := LevelSet(test.m, level, bound, maxValue, math.MaxInt)
+				if !ok {
+					t.Fatalf("t = %d: the walk gave up without a limit", level)
+				}
+				sort.Slice(levelSet, func(i, j int) bool { return levelSet[i] < levelSet[j] })
+				if len(levelSet) != len(expected[uint64(level)]) {
+					t.Fatalf("t = %d: found %d x, expected %d", level, len(levelSet), len(expected[uint64(level)]))
+				}
+				for i, x := range levelSet {
+					if x != expected[uint64(level)][i] {
+						t.Fatalf("t = %d: found %d, expected %d", level, x, expected[uint64(level)][i])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestLevelSetLimit(t *testing.T) {
+	if _, ok := LevelSet(InverseRPrime, 25, 100_000, math.MaxUint64, 100_000); ok {
+		t.Fatal("the walk should give up once it has found more than 100000 values")
+	}
+}
@@ -0,0 +1,173 @@
+package shared
+
+import (
+	"fmt"
+	"math/bits"
+	"sync"
+)
+
+// JumpTable evaluates f, g and h by processing k low bits of n at a time with the Terras map
+//
+// T(x) = { x/2           if x ≡ 0 (mod 2)
+// .... = { (3x+1)/2      if x ≡ 1 (mod 2)
+//
+// The parity of the first k steps of T depends only on n mod 2^k, so writing n = a·2^k + b,
+//
+// T^k(n) = 3^c(b)·a + T^k(b)
+//
+// Where c(b) is the number of odd steps among the first k steps of b. Each odd step of T is two standard steps and
+// each even step is one, so a jump covers k + c(b) standard steps. Once n ≤ 2^k a jump could pass through 1, so the
+// remaining steps are looked up in a table of exact values for every n ≤ 2^k.
+//
+// g counts the odd steps of T, plus one if n is even, and h counts the runs of even steps of T (see A160541), so both
+// can be recovered from the parity of each block.
+type JumpTable struct {
+	k     uint
+	mask  uint64
+	pow3  []uint64
+	block []jumpBlock
+	tail  []jumpTail
+}
+
+// jumpBlock describes the first k steps of T for a residue b mod 2^k
+type jumpBlock struct {
+	value       uint64 // T^k(b)
+	odd         uint8  // c(b), the number of odd steps
+	transitions uint8  // the number of even steps followed by an odd step
+	last        uint8  // the parity of the last step
+}
+
+// jumpTail holds the exact stopping times of T for n ≤ 2^k
+type jumpTail struct {
+	normalTime uint32 // f(n)
+	odd        uint32 // the number of odd steps of T
+	runs       uint32 // the number of runs of even steps of T
+}
+
+// NewJumpTable builds the tables needed to process k bits at a time, where 1 ≤ k ≤ 20
+func NewJumpTable(k uint) *JumpTable {
+	if k < 1 || k > 20 {
+		panic(fmt.Sprintf("jump table size must be in the range 1..20: %d", k))
+	}
+	size := uint64(1) << k
+	t := &JumpTable{
+		k:     k,
+		mask:  size - 1,
+		pow3:  make([]uint64, k+1),
+		block: make([]jumpBlock, size),
+		tail:  make([]jumpTail, size+1),
+	}
+	t.pow3[0] = 1
+	for i := uint(1); i <= k; i++ {
+		t.pow3[i] = t.pow3[i-1] * 3
+	}
+	for b := uint64(0); b < size; b++ {
+		x := b
+		block := jumpBlock{}
+		for i := uint(0); i < k; i++ {
+			parity := uint8(x & 1)
+			if i > 0 && block.last == 0 && parity == 1 {
+				block.transitions++
+			}
+			block.last = parity
+			if parity == 1 {
+				x = x + x>>1 + 1 // (3x+1)/2
+				block.odd++
+			} else {
+				x >>= 1 // x/2
+			}
+		}
+		block.value = x
+		t.block[b] = block
+	}
+	for n := uint64(1); n <= size; n++ {
+		x := n
+		tail := jumpTail{}
+		for x != 1 {
+			if x&1 == 1 {
+				x = x + x>>1 + 1 // (3x+1)/2
+				tail.normalTime += 2
+				tail.odd++
+			} else {
+				x >>= 1 // x/2
+				tail.normalTime++
+				if x&1 == 1 {
+					tail.runs++
+				}
+			}
+		}
+		t.tail[n] = tail
+	}
+	return t
+}
+
+// Bits returns the number of bits processed per jump
+func (t *JumpTable) Bits() uint {
+	return t.k
+}
+
+// StoppingTimes returns f(n), g(n) and h(n), or false if the orbit leaves the range of uint64
+func (t *JumpTable) StoppingTimes(n uint64) (uint64, uint64, uint64, bool) {
+	normalTime, odd, runs := uint64(0), uint64(0), uint64(0)
+	even := n&1 == 0
+	// the parity of the last step of the previous block, 1 before the first block so no run is counted
+	last := uint8(1)
+	for n > t.mask+1 {
+		block := &t.block[n&t.mask]
+		if last == 0 && n&1 == 1 {
+			runs++
+		}
+		normalTime += uint64(t.k) + uint64(block.odd)
+		odd += uint64(block.odd)
+		runs += uint64(block.transitions)
+		last = block.last
+		next, ok := t.jump(n, block)
+		if !ok {
+			return 0, 0, 0, false
+		}
+		n = next
+	}
+	if last == 0 && n&1 == 1 {
+		runs++
+	}
+	tail := &t.tail[n]
+	normalTime += uint64(tail.normalTime)
+	odd += uint64(tail.odd)
+	runs += uint64(tail.runs)
+	if even {
+		odd++
+	}
+	return normalTime, odd, runs, true
+}
+
+// jump returns T^k(n) = 3^c(b)·a + T^k(b) for n = a·2^k + b, or false if it leaves the range of uint64
+func (t *JumpTable) jump(n uint64, block *jumpBlock) (uint64, bool) {
+	hi, lo := bits.Mul64(n>>t.k, t.pow3[block.odd])
+	next, carry := bits.Add64(lo, block.value, 0)
+	return next, hi == 0 && carry == 0
+}
+
+// Step jumps k steps of T ahead when n > 2^k, and otherwise takes a single standard step. It returns the next value
+// along with the number of standard steps covered, or false if the step leaves the range of uint64.
+func (t *JumpTable) Step(n uint64) (uint64, uint64, bool) {
+	if n <= t.mask+1 {
+		return MapC.Step(n)
+	}
+	block := &t.block[n&t.mask]
+	next, ok := t.jump(n, block)
+	return next, uint64(t.k) + uint64(block.odd), ok
+}
+
+// JumpMap returns the map that jumps k steps of T at a time using a JumpTable, which is built on first use
+func JumpMap(k uint) Map {
+	var once sync.Once
+	var t *JumpTable
+	return Map{
+		Name:        fmt.Sprintf("T^%d", k),
+		Description: fmt.Sprintf("a·2^%d + b to 3^c(b)·a + T^%d(b)", k, k),
+		Step: func(x uint64) (uint64, uint64, bool) {
+			once.Do(func() { t = NewJumpTable(k) })
+			return t.Step(x)
+		},
+	}
+}
@@ -0,0 +1,45 @@
+package shared
+
+import (
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestJumpTable(t *testing.T) {
+	inputs := append(benchmarkInputs(60, 1<<10), maxOdd, maxOdd+2, 1<<63+1, math.MaxUint64)
+	for n := uint64(1); n <= 1<<12; n++ {
+		inputs = append(inputs, n)
+	}
+	for _, k := range []uint{1, 8, 16} {
+		table := NewJumpTable(k)
+		for _, n := range inputs {
+			// T takes (3x+1)/2 in one step, so the jump table can follow orbits where 3x+1 leaves the range of uint64
+			wide := new(big.Int).SetUint64(n)
+			f, g, h := CollatzStoppingTimeFWide(wide), CollatzStoppingTimeGWide(wide), CollatzStoppingTimeHWide(wide)
+			jf, jg, jh, ok := table.StoppingTimes(n)
+			if !ok {
+				if f.Max.BitLen() <= 64 {
+					t.Fatalf("%d-bit jump table overflows for %d, whose orbit stays below 2^64", k, n)
+				}
+				continue
+			}
+			if jf != f.Time || jg != g.Time || jh != h.Time {
+				t.Fatalf("%d-bit jump table gives %d, %d, %d for %d, expected %d, %d, %d", k, jf, jg, jh, n, f.Time, g.Time, h.Time)
+			}
+		}
+	}
+}
+
+func TestMaps(t *testing.T) {
+	inputs := append(benchmarkInputs(60, 1<<10), maxOdd, maxOdd+2, math.MaxUint64)
+	for _, m := range Maps {
+		for _, n := range inputs {
+			f := CollatzStoppingTimeF(n)
+			_, normalTime, ok := m.StoppingTime(n)
+			if ok != !f.Overflow || (ok && normalTime != f.StandardTime) {
+				t.Fatalf("%s covers %d standard steps of %d with ok = %t, expected %+v", m.Name, normalTime, n, ok, f)
+			}
+		}
+	}
+}
@@ -0,0 +1,253 @@
+package shared
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// MinLanes and MaxLanes bound the number of orbits a Lanes follows together
+const (
+	MinLanes = 4
+	MaxLanes = 16
+)
+
+// Lanes evaluates f, g or h for many n by following several orbits together in lock-step, one step of each per round.
+//
+// Each step is computed without branching on the state of its orbit, and the steps of different
+// orbits are independent, so the processor can overlap them instead of waiting on the latency of a single orbit. When
+// an orbit reaches 1 its lane is refilled with the next n. The Result of each n is the same as the function of the
+// Lanes, including on overflow. On a single core this is slower than evaluating each n on its own, see BenchmarkLanes.
+type Lanes struct {
+	width int
+	kind  laneKind
+}
+
+// laneKind selects the map followed by a Lanes
+type laneKind int
+
+const (
+	laneF laneKind = iota
+	laneG
+	laneH
+)
+
+// lane is the state of one orbit followed by a Lanes. It only keeps the counts that change every step, the even steps
+// are the standard steps that are not odd. overflow is 1 once the orbit leaves the range of uint64, and is kept as a
+// number so that the steps can mask with it.
+type lane struct {
+	x               uint64
+	index           int
+	time            uint64
+	standardTime    uint64
+	odd             uint64
+	max             uint64
+	coefficientTime uint64
+	overflow        uint64
+}
+
+// start starts the orbit of n as the index-th result
+func (s *lane) start(n uint64, index int) {
+	*s = lane{x: n, index: index, max: n}
+}
+
+// result returns the Result of the orbit so far
+func (s *lane) result() Result {
+	return Result{
+		Time:            s.time,
+		StandardTime:    s.standardTime,
+		Max:             s.max,
+		Odd:             s.odd,
+		Even:            s.standardTime - s.odd,
+		CoefficientTime: s.coefficientTime,
+		Overflow:        s.overflow == 1,
+	}
+}
+
+// NewLanesF returns a Lanes for CollatzStoppingTimeF following width orbits at a time
+func NewLanesF(width int) *Lanes {
+	return newLanes(width, laneF)
+}
+
+// NewLanesG returns a Lanes for CollatzStoppingTimeG following width orbits at a time
+func NewLanesG(width int) *Lanes {
+	return newLanes(width, laneG)
+}
+
+// NewLanesH returns a Lanes for CollatzStoppingTimeH following width orbits at a time
+func NewLanesH(width int) *Lanes {
+	return newLanes(width, laneH)
+}
+
+func newLanes(width int, kind laneKind) *Lanes {
+	if width < MinLanes || width > MaxLanes {
+		panic(fmt.Sprintf("lanes must be in the range %d..%d: %d", MinLanes, MaxLanes, width))
+	}
+	return &Lanes{width: width, kind: kind}
+}
+
+// Width returns the number of orbits followed at a time
+func (l *Lanes) Width() int {
+	return l.width
+}
+
+// Evaluate stores the Result of each n ≥ 1 in ns in results. It is safe for concurrent use.
+func (l *Lanes) Evaluate(ns []uint64, results []Result) {
+	l.evaluate(len(ns), func(i int) uint64 { return ns[i] }, results)
+}
+
+// EvaluateRange stores the Result of each n in [from, from+len(results)) in results, where from ≥ 1. It is safe for
+// concurrent use.
+func (l *Lanes) EvaluateRange(from uint64, results []Result) {
+	l.evaluate(len(results), func(i int) uint64 { return from + uint64(i) }, results)
+}
+
+func (l *Lanes) evaluate(count int, input func(i int) uint64, results []Result) {
+	var lanes [MaxLanes]lane
+	next := 0
+	active := 0
+	for ; active < l.width && next < count; active++ {
+		lanes[active].start(input(next), next)
+		next++
+	}
+	// done is set by the steps when an orbit reaches 1 or overflows, and to start with in case n = 1
+	done := true
+	for active > 0 {
+		// retire the orbits that are done, refilling their lanes, or moving the last lane into them once every n has
+		// been started. a refilled lane is checked again since it may already be done.
+		for i := 0; done && i < active; {
+			s := &lanes[i]
+			if s.x != 1 && s.overflow == 0 {
+				i++
+				continue
+			}
+			results[s.index] = s.result()
+			if next < count {
+				s.start(input(next), next)
+				next++
+				continue
+			}
+			active--
+			*s = lanes[active]
+		}
+		switch l.kind {
+		case laneF:
+			done = stepLanesF(lanes[:active])
+		case laneG:
+			done = stepLanesG(lanes[:active])
+		case laneH:
+			done = stepLanesH(lanes[:active])
+		}
+	}
+}
+
+// The steps below follow every lane without branching on its state. The parity, the overflow check, the coefficient
+// stopping time and reaching 1 each become a mask of all ones or all zeros, and a lane that overflows keeps the state
+// it had before the step. The steps are written out in the loops rather than called, since calls would cost as much as
+// the steps.
+
+// stepLanesF takes a step of C in each lane, x/2 or 3x+1 selected by masking with the parity, and reports whether any
+// orbit is done.
+func stepLanesF(lanes []lane) bool {
+	done := uint64(0)
+	for i := range lanes {
+		s := &lanes[i]
+		x := s.x
+		odd := x & 1
+		over := odd & less(maxOdd, x)
+		keep := over - 1
+		next := (x>>1)&^-odd | (x<<1+x+1)&-odd
+		found := isZero(s.coefficientTime) & (1 ^ less(s.standardTime-s.odd+(odd^1), coefficientEvenLane(s.odd+odd))) & keep
+		s.coefficientTime |= (s.odd + odd + coefficientEvenLane(s.odd+odd)) & -found
+		s.odd += odd & keep
+		s.standardTime += keep & 1
+		s.time += keep & 1
+		s.x = next&keep | x&^keep
+		s.max = maxUint64(s.max, s.x)
+		s.overflow = over
+		done |= over | isZero(s.x^1)
+	}
+	return done != 0
+}
+
+// stepLanesG takes a step of g in each lane, x/2^m for even x or R(x) for odd x, and reports whether any orbit is done.
+// The 3x+1 of R is added as 2x+1 masked by the parity, so even x is left as it is before dividing out 2^m.
+func stepLanesG(lanes []lane) bool {
+	done := uint64(0)
+	for i := range lanes {
+		s := &lanes[i]
+		x := s.x
+		odd := x & 1
+		over := odd & less(maxOdd, x)
+		keep := over - 1
+		next := x + (x<<1+1)&-odd
+		m := uint64(bits.TrailingZeros64(next))
+		next >>= m // x/2^m
+		found := isZero(s.coefficientTime) & (1 ^ less(s.standardTime-s.odd+m, coefficientEvenLane(s.odd+odd))) & keep
+		s.coefficientTime |= (s.odd + odd + coefficientEvenLane(s.odd+odd)) & -found
+		s.odd += odd & keep
+		s.standardTime += (odd + m) & keep
+		s.time += keep & 1
+		s.x = next&keep | x&^keep
+		s.max = maxUint64(s.max, s.x)
+		s.overflow = over
+		done |= over | isZero(s.x^1)
+	}
+	return done != 0
+}
+
+// stepLanesH takes a step of h in each lane, x/2^m for even x or R′(x) for odd x, and reports whether any orbit is
+// done. RPrime takes either step without branching on the parity.
+func stepLanesH(lanes []lane) bool {
+	done := uint64(0)
+	for i := range lanes {
+		s := &lanes[i]
+		x := s.x
+		next, v, m, ok := RPrime(x)
+		over := 1 ^ fromBool(ok)
+		keep := over - 1
+		found := isZero(s.coefficientTime) & (1 ^ less(s.standardTime-s.odd+v+m, coefficientEvenLane(s.odd+v))) & keep
+		s.coefficientTime |= (s.odd + v + coefficientEvenLane(s.odd+v)) & -found
+		s.odd += v & keep
+		s.standardTime += (2*v + m) & keep
+		s.time += keep & 1
+		s.x = next&keep | x&^keep
+		s.max = maxUint64(s.max, s.x)
+		s.overflow = over
+		done |= over | isZero(s.x^1)
+	}
+	return done != 0
+}
+
+// coefficientEvenLane returns coefficientEven(a) for the a a lane can reach. The orbits of uint64 have far fewer than
+// len(coefficientEvens) odd steps, so the check is never taken and costs nothing to predict.
+func coefficientEvenLane(a uint64) uint64 {
+	if a >= uint64(len(coefficientEvens)) {
+		return coefficientEven(a)
+	}
+	return coefficientEvens[a]
+}
+
+// maxUint64 returns the larger of a and b without branching
+func maxUint64(a, b uint64) uint64 {
+	return a ^ (a^b)&-less(a, b)
+}
+
+// less returns 1 if a < b and 0 otherwise, without branching
+func less(a, b uint64) uint64 {
+	_, borrow := bits.Sub64(a, b, 0)
+	return borrow
+}
+
+// isZero returns 1 if x = 0 and 0 otherwise, without branching
+func isZero(x uint64) uint64 {
+	return 1 ^ (x|-x)>>63
+}
+
+// fromBool returns 1 for true and 0 for false. The compiler turns it into a conditional set rather than a branch.
+func fromBool(b bool) uint64 {
+	var u uint64
+	if b {
+		u = 1
+	}
+	return u
+}
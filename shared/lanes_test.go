@@ -0,0 +1,63 @@
+package shared
+
+import (
+	"fmt"
+	"testing"
+)
+
+// laneFunctions are the functions evaluated by each kind of Lanes
+var laneFunctions = []struct {
+	name     string
+	fn       func(n uint64) Result
+	newLanes func(width int) *Lanes
+}{
+	{name: "f", fn: CollatzStoppingTimeF, newLanes: NewLanesF},
+	{name: "g", fn: CollatzStoppingTimeG, newLanes: NewLanesG},
+	{name: "h", fn: CollatzStoppingTimeH, newLanes: NewLanesH},
+}
+
+func TestLanes(t *testing.T) {
+	inputs := append(benchmarkInputs(60, 1<<10), 1, 2, 3, 27, maxOdd, maxOdd+2, 1<<63+1, 1<<64-1)
+	for _, l := range laneFunctions {
+		for width := MinLanes; width <= MaxLanes; width++ {
+			results := make([]Result, len(inputs))
+			l.newLanes(width).Evaluate(inputs, results)
+			for i, n := range inputs {
+				if expected := l.fn(n); results[i] != expected {
+					t.Fatalf("%s with %d lanes gives %+v for %d, expected %+v", l.name, width, results[i], n, expected)
+				}
+			}
+			l.newLanes(width).EvaluateRange(1, results)
+			for i, r := range results {
+				if expected := l.fn(uint64(i + 1)); r != expected {
+					t.Fatalf("%s with %d lanes gives %+v for %d, expected %+v", l.name, width, r, i+1, expected)
+				}
+			}
+		}
+	}
+}
+
+// BenchmarkLanes compares evaluating the same n one at a time and in lock-step lanes of several widths
+func BenchmarkLanes(b *testing.B) {
+	for _, l := range laneFunctions {
+		for _, bitLen := range benchmarkMagnitudes {
+			inputs := benchmarkInputs(bitLen, 1<<10)
+			results := make([]Result, len(inputs))
+			b.Run(fmt.Sprintf("%s/2^%d/scalar", l.name, bitLen), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					for j, n := range inputs {
+						results[j] = l.fn(n)
+					}
+				}
+			})
+			for _, width := range []int{4, 8, 16} {
+				lanes := l.newLanes(width)
+				b.Run(fmt.Sprintf("%s/2^%d/lanes-%d", l.name, bitLen, width), func(b *testing.B) {
+					for i := 0; i < b.N; i++ {
+						lanes.Evaluate(inputs, results)
+					}
+				})
+			}
+		}
+	}
+}
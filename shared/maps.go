@@ -0,0 +1,113 @@
+package shared
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// Map is an accelerated version of the Collatz map C, such as R or R′, that jumps ahead one or more standard steps in
+// an orbit at a time.
+type Map struct {
+	Name        string
+	Description string
+	// Step returns the next value after x > 1, along with the number of standard steps it jumps ahead in the orbit, or
+	// false if the step leaves the range of uint64
+	Step func(x uint64) (uint64, uint64, bool)
+}
+
+// StoppingTime returns the number of steps of m needed to reach 1 from n, along with the number of standard steps they
+// jump ahead in total, which equals f(n) for any correct acceleration. It returns false if the orbit leaves the range
+// of uint64, along with the steps taken before it.
+func (m Map) StoppingTime(n uint64) (uint64, uint64, bool) {
+	time, normalTime := uint64(0), uint64(0)
+	for n != 1 {
+		next, steps, ok := m.Step(n)
+		if !ok {
+			return time, normalTime, false
+		}
+		n = next
+		normalTime += steps
+		time++
+	}
+	return time, normalTime, true
+}
+
+// Compose returns the map that applies m up to k times per step, stopping early if it reaches 1
+func Compose(m Map, k int) Map {
+	return Map{
+		Name:        fmt.Sprintf("%s^%d", m.Name, k),
+		Description: fmt.Sprintf("%d consecutive steps of %s", k, m.Name),
+		Step: func(x uint64) (uint64, uint64, bool) {
+			normalTime := uint64(0)
+			for i := 0; i < k && x != 1; i++ {
+				next, steps, ok := m.Step(x)
+				if !ok {
+					return 0, 0, false
+				}
+				x = next
+				normalTime += steps
+			}
+			return x, normalTime, true
+		},
+	}
+}
+
+// divideOut returns x/2^m, where 2^m is the highest power of 2 that divides x, along with m
+func divideOut(x uint64) (uint64, uint64) {
+	m := uint64(bits.TrailingZeros64(x))
+	return x >> m, m // x/2^m
+}
+
+// MapC is the standard Collatz map C, the step of f
+var MapC = Map{
+	Name:        "C",
+	Description: "x/2 or 3x+1",
+	Step: func(x uint64) (uint64, uint64, bool) {
+		if x&1 == 1 {
+			if x > maxOdd {
+				return 0, 0, false
+			}
+			return x<<1 + x + 1, 1, true // 3x+1
+		}
+		return x >> 1, 1, true // x/2
+	},
+}
+
+// MapR is the reduced Collatz map R, along with x/2^m for even x, the step of g
+var MapR = Map{
+	Name:        "R",
+	Description: "x/2^m or (3x+1)/2^m",
+	Step: func(x uint64) (uint64, uint64, bool) {
+		if x&1 == 1 {
+			if x > maxOdd {
+				return 0, 0, false
+			}
+			x, steps := R(x)
+			return x, steps, true
+		}
+		x, m := divideOut(x)
+		return x, m, true
+	},
+}
+
+// MapRPrime is the main result R′, along with x/2^m for even x, the step of h
+var MapRPrime = Map{
+	Name:        "R′",
+	Description: "x/2^m or ((3/2)^ν2(x+1) (x+1) - 1)/2^m",
+	Step: func(x uint64) (uint64, uint64, bool) {
+		x, v, m, ok := RPrime(x)
+		return x, 2*v + m, ok
+	},
+}
+
+// Maps are the registered accelerations of C, which are verified against f and compared to f, g and h by the accel
+// command. Further accelerations are added here.
+var Maps = []Map{
+	MapC,
+	MapR,
+	MapRPrime,
+	Compose(MapRPrime, 2),
+	Compose(MapRPrime, 4),
+	JumpMap(8),
+	JumpMap(16),
+}
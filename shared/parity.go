@@ -0,0 +1,139 @@
+package shared
+
+import (
+	"fmt"
+	"math/big"
+	"math/bits"
+)
+
+// ParityVector returns the parities x mod 2 of the first k values x = n, C(n), C(C(n)), ... of the standard map.
+// Trajectories that reach 1 continue around the cycle 1 → 4 → 2 → 1.
+func ParityVector(n uint64, k int) []uint8 {
+	vector := make([]uint8, k)
+	for i := range vector {
+		vector[i] = uint8(n & 1)
+		if n&1 == 1 {
+			n = n<<1 + n + 1 // 3n+1
+		} else {
+			n >>= 1 // n/2
+		}
+	}
+	return vector
+}
+
+// TerrasVector returns the parities of the first k values of the trajectory of n under the Terras map T, where an odd
+// step (3x+1)/2 combines the 3x+1 and x/2 steps of C
+func TerrasVector(n uint64, k int) []uint8 {
+	vector := make([]uint8, k)
+	for i := range vector {
+		vector[i] = uint8(n & 1)
+		if n&1 == 1 {
+			n = n + n>>1 + 1 // (3n+1)/2
+		} else {
+			n >>= 1 // n/2
+		}
+	}
+	return vector
+}
+
+// TerrasResidueClass returns the residue r mod 2^len(vector) of every n whose first len(vector) parities under the
+// Terras map match the vector, which Terras showed is unique. The vector may have at most 64 parities.
+//
+// Writing n = r + 2^i·a for the residue r mod 2^i found so far, T^i(n) = T^i(r) + 3^c·a, so the parity of step i
+// decides bit i of n.
+func TerrasResidueClass(vector []uint8) (uint64, error) {
+	if len(vector) > 64 {
+		return 0, fmt.Errorf("at most 64 parities are supported: %d", len(vector))
+	}
+	r := uint64(0)
+	x := new(big.Int)
+	double := new(big.Int)
+	one := big.NewInt(1)
+	for i, parity := range vector {
+		if parity > 1 {
+			return 0, fmt.Errorf("parity %d is not 0 or 1: %d", i, parity)
+		}
+		// T^i(r) with the bits of r found so far
+		x.SetUint64(r)
+		for j := 0; j < i; j++ {
+			if x.Bit(0) == 1 {
+				x.Rsh(x.Add(x.Add(x, double.Lsh(x, 1)), one), 1) // (3x+1)/2
+			} else {
+				x.Rsh(x, 1) // x/2
+			}
+		}
+		if uint8(x.Bit(0)) != parity {
+			r |= 1 << i
+		}
+	}
+	return r, nil
+}
+
+// ResidueClass returns the residue r mod 2^b of every n whose first parities under the standard map C match the
+// vector, where b is the number of parities left after dropping the even step that always follows 3x+1.
+func ResidueClass(vector []uint8) (uint64, uint, error) {
+	terras := make([]uint8, 0, len(vector))
+	for i := 0; i < len(vector); i++ {
+		terras = append(terras, vector[i])
+		if vector[i] == 1 && i+1 < len(vector) {
+			if vector[i+1] != 0 {
+				return 0, 0, fmt.Errorf("parity %d follows 3x+1 and must be 0", i+1)
+			}
+			i++
+		}
+	}
+	r, err := TerrasResidueClass(terras)
+	return r, uint(len(terras)), err
+}
+
+// Shape is a single step of h: V = ν2(x+1) multiplications of x+1 by 3/2, followed by M divisions by 2. V is 0 for the
+// first step of an even n.
+type Shape struct {
+	V uint
+	M uint
+}
+
+func (s Shape) String() string {
+	return fmt.Sprintf("%d:%d", s.V, s.M)
+}
+
+// RPrimeShapes returns the shape of every step of h from n down to 1, or an error if the orbit leaves the range of
+// uint64
+func RPrimeShapes(n uint64) ([]Shape, error) {
+	shapes := make([]Shape, 0)
+	if n&1 == 0 {
+		m := uint(bits.TrailingZeros64(n))
+		n >>= m
+		shapes = append(shapes, Shape{V: 0, M: m})
+	}
+	for n != 1 {
+		next, v, m, ok := RPrime(n)
+		if !ok {
+			return nil, fmt.Errorf("overflow after %d steps of h at %d", len(shapes), n)
+		}
+		shapes = append(shapes, Shape{V: uint(v), M: uint(m)})
+		n = next
+	}
+	return shapes, nil
+}
+
+// ShapeResidueClass returns the residue r mod 2^b of every n whose first steps of h have the given shapes. Each shape
+// is V odd steps of the Terras map followed by M even steps, and the value after the last shape is odd, so b is the
+// total of V + M plus one.
+func ShapeResidueClass(shapes []Shape) (uint64, uint, error) {
+	terras := make([]uint8, 0)
+	for i, shape := range shapes {
+		if shape.M == 0 || (shape.V == 0 && i > 0) {
+			return 0, 0, fmt.Errorf("shape %d must have M ≥ 1, and V ≥ 1 unless it is the first: %s", i, shape)
+		}
+		for j := uint(0); j < shape.V; j++ {
+			terras = append(terras, 1)
+		}
+		for j := uint(0); j < shape.M; j++ {
+			terras = append(terras, 0)
+		}
+	}
+	terras = append(terras, 1)
+	r, err := TerrasResidueClass(terras)
+	return r, uint(len(terras)), err
+}
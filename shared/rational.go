@@ -0,0 +1,29 @@
+package shared
+
+import (
+	"math/big"
+)
+
+// RPrimeRat evaluates R′ literally as it is defined in the README using exact rational arithmetic, where x is odd and
+// 2^m is the highest power of 2 that divides the numerator
+//
+// R′(x) = ((3/2)^ν2(x+1) (x+1) - 1)/2^m
+//
+// It returns R′(x) along with ν2(x+1) and m. It is much slower than RPrime, and exists as a reference to check RPrime
+// and CollatzStoppingTimeH against.
+func RPrimeRat(x *big.Int) (*big.Int, uint, uint) {
+	x1 := new(big.Int).Add(x, big.NewInt(1))
+	v := x1.TrailingZeroBits()
+	r := new(big.Rat).SetFrac(
+		new(big.Int).Exp(big.NewInt(3), big.NewInt(int64(v)), nil),
+		new(big.Int).Lsh(big.NewInt(1), v),
+	)
+	r.Mul(r, new(big.Rat).SetInt(x1))
+	r.Sub(r, big.NewRat(1, 1))
+	if !r.IsInt() {
+		panic("(3/2)^ν2(x+1) (x+1) - 1 is not an integer")
+	}
+	numerator := r.Num()
+	m := numerator.TrailingZeroBits()
+	return new(big.Int).Rsh(numerator, m), v, m
+}
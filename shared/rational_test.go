@@ -0,0 +1,60 @@
+package shared
+
+import (
+	"math"
+	"math/big"
+	"math/bits"
+	"testing"
+)
+
+// FuzzRPrime checks RPrime against the literal RPrimeRat for odd n, and CollatzStoppingTimeH against the trajectory of
+// n under RPrimeRat
+func FuzzRPrime(f *testing.F) {
+	for _, n := range []uint64{1, 3, 7, 27, 255, 1<<40 - 1, maxOdd, math.MaxUint64 - 2, math.MaxUint64} {
+		f.Add(n)
+	}
+	f.Fuzz(func(t *testing.T, n uint64) {
+		if n == 0 {
+			return
+		}
+		x := n | 1
+		expected, v, m := RPrimeRat(new(big.Int).SetUint64(x))
+		actual, actualV, actualM, ok := RPrime(x)
+		// the numerator (3/2)^ν2(x+1) (x+1) - 1 is the largest value reached during the step
+		fits := expected.BitLen()+int(m) <= 64
+		if ok != fits {
+			t.Fatalf("RPrime(%d) ok = %t, expected %t", x, ok, fits)
+		}
+		if ok && (actual != expected.Uint64() || actualV != uint64(v) || actualM != uint64(m)) {
+			t.Fatalf("RPrime(%d) = %d, %d, %d, expected %s, %d, %d", x, actual, actualV, actualM, expected, v, m)
+		}
+
+		h := CollatzStoppingTimeH(n)
+		r := Result{Max: n}
+		y := n
+		if y&1 == 0 {
+			m := uint64(bits.TrailingZeros64(y))
+			y >>= m
+			r.Time, r.StandardTime = 1, m
+		}
+		big1 := big.NewInt(1)
+		for z := new(big.Int).SetUint64(y); z.Cmp(big1) != 0; {
+			next, v, m := RPrimeRat(z)
+			if next.BitLen()+int(m) > 64 {
+				if !h.Overflow {
+					t.Fatalf("CollatzStoppingTimeH(%d) = %+v, expected overflow at %s", n, h, z)
+				}
+				return
+			}
+			z = next
+			r.Time++
+			r.StandardTime += uint64(2*v + m)
+			if z.Uint64() > r.Max {
+				r.Max = z.Uint64()
+			}
+		}
+		if h.Overflow || h.Time != r.Time || h.StandardTime != r.StandardTime || h.Max != r.Max {
+			t.Fatalf("CollatzStoppingTimeH(%d) = %+v, expected time %d, standard time %d, max %d", n, h, r.Time, r.StandardTime, r.Max)
+		}
+	})
+}
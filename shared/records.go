@@ -0,0 +1,167 @@
+package shared
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+)
+
+// Record is an n whose value, such as its total stopping time or the largest value reached by its trajectory, exceeds
+// that of every smaller n.
+type Record struct {
+	N     uint64
+	Value uint64
+}
+
+// PathRecords are the published path records of f, the n whose trajectory reaches a larger value than the trajectory
+// of every smaller n, along with the largest value reached.
+//
+// https://oeis.org/A006884
+// https://oeis.org/A006885
+var PathRecords = []Record{
+	{N: 1, Value: 1},
+	{N: 2, Value: 2},
+	{N: 3, Value: 16},
+	{N: 7, Value: 52},
+	{N: 15, Value: 160},
+	{N: 27, Value: 9232},
+	{N: 255, Value: 13120},
+	{N: 447, Value: 39364},
+	{N: 639, Value: 41524},
+	{N: 703, Value: 250504},
+	{N: 1819, Value: 1276936},
+	{N: 4255, Value: 6810136},
+	{N: 4591, Value: 8153620},
+	{N: 9663, Value: 27114424},
+	{N: 20895, Value: 50143264},
+	{N: 26623, Value: 106358020},
+	{N: 31911, Value: 121012864},
+	{N: 60975, Value: 593279152},
+	{N: 77671, Value: 1570824736},
+	{N: 113383, Value: 2482111348},
+	{N: 138367, Value: 2798323360},
+	{N: 159487, Value: 17202377752},
+	{N: 270271, Value: 24648077896},
+	{N: 665215, Value: 52483285312},
+	{N: 704511, Value: 56991483520},
+	{N: 1042431, Value: 90239155648},
+	{N: 1212415, Value: 139646736808},
+	{N: 1441407, Value: 151629574372},
+	{N: 1875711, Value: 155904349696},
+	{N: 1988859, Value: 156914378224},
+	{N: 2643183, Value: 190459818484},
+	{N: 2684647, Value: 352617812944},
+	{N: 3041127, Value: 622717901620},
+	{N: 3873535, Value: 858555169576},
+	{N: 4637979, Value: 1318802294932},
+	{N: 5656191, Value: 2412493616608},
+	{N: 6416623, Value: 4799996945368},
+	{N: 6631675, Value: 60342610919632},
+	{N: 19638399, Value: 306296925203752},
+	{N: 38595583, Value: 474637698851092},
+	{N: 80049391, Value: 2185143829170100},
+}
+
+// PathRecordBound returns the largest value reached by the trajectory under C of any n ≤ bound, from PathRecords, or
+// false when the bound is beyond the last of them
+func PathRecordBound(bound uint64) (uint64, bool) {
+	if bound >= PathRecords[len(PathRecords)-1].N {
+		return 0, false
+	}
+	maxValue := uint64(0)
+	for _, record := range PathRecords {
+		if record.N <= bound {
+			maxValue = record.Value
+		}
+	}
+	return maxValue, true
+}
+
+// recordsChunk is the number of consecutive n each worker scans at a time
+const recordsChunk = 1 << 16
+
+// FindRecords returns every n in [from, limit) whose value exceeds best and the value of every smaller n in the range.
+// To resume after a known record, from is the n after it and best is its value.
+func FindRecords(from uint64, limit uint64, best uint64, value func(n uint64) uint64) []Record {
+	if from >= limit {
+		return nil
+	}
+	var wg sync.WaitGroup
+	workers := uint64(runtime.GOMAXPROCS(0))
+	chunks := (limit - from + recordsChunk - 1) / recordsChunk
+	// records local to each chunk, a global record is always a record within its own chunk
+	local := make([][]Record, chunks)
+	for w := uint64(0); w < workers; w++ {
+		wg.Add(1)
+		go (func(worker uint64, workerCount uint64, limit uint64) {
+			defer wg.Done()
+			for c := worker; c < chunks; c += workerCount {
+				chunkBest := best
+				end := from + (c+1)*recordsChunk
+				if end > limit || end < from {
+					end = limit
+				}
+				for i := from + c*recordsChunk; i < end; i++ {
+					if v := value(i); v > chunkBest {
+						chunkBest = v
+						local[c] = append(local[c], Record{N: i, Value: v})
+					}
+				}
+			}
+		})(w, workers, limit)
+	}
+	wg.Wait()
+
+	var records []Record
+	for _, chunk := range local {
+		for _, record := range chunk {
+			if record.Value > best {
+				best = record.Value
+				records = append(records, record)
+			}
+		}
+	}
+	return records
+}
+
+// CheckRecords compares the n of the records found in (resumeN, limit) against the published record n known in the
+// same range, and returns the number of records that match. With resumeN 0 the records start at n = 1.
+func CheckRecords(records []Record, known []uint64, resumeN uint64, limit uint64) (int, error) {
+	if len(known) == 0 {
+		return 0, fmt.Errorf("no published records")
+	}
+	if resumeN > 0 {
+		published := false
+		for _, n := range known {
+			published = published || n == resumeN
+		}
+		if !published {
+			return 0, fmt.Errorf("n = %d is not a published record", resumeN)
+		}
+	}
+	last := known[len(known)-1]
+	expected := make([]uint64, 0)
+	for _, n := range known {
+		if (resumeN == 0 || n > resumeN) && n < limit {
+			expected = append(expected, n)
+		}
+	}
+	found := make([]Record, 0)
+	for _, record := range records {
+		if record.N <= last {
+			found = append(found, record)
+		}
+	}
+	for i := 0; i < len(found) || i < len(expected); i++ {
+		if i >= len(found) {
+			return 0, fmt.Errorf("missing published record n = %d", expected[i])
+		}
+		if i >= len(expected) {
+			return 0, fmt.Errorf("found n = %d with value %d, which is not a published record", found[i].N, found[i].Value)
+		}
+		if found[i].N != expected[i] {
+			return 0, fmt.Errorf("found n = %d with value %d, expected n = %d", found[i].N, found[i].Value, expected[i])
+		}
+	}
+	return len(found), nil
+}
@@ -0,0 +1,188 @@
+package shared
+
+import (
+	"fmt"
+	"testing"
+)
+
+// delayRecords are the published delay records of f, the n whose standard total stopping time exceeds that of every
+// smaller n, along with their stopping times.
+//
+// https://oeis.org/A006877
+// https://oeis.org/A006878
+var delayRecords = []Record{
+	{N: 1, Value: 0},
+	{N: 2, Value: 1},
+	{N: 3, Value: 7},
+	{N: 6, Value: 8},
+	{N: 7, Value: 16},
+	{N: 9, Value: 19},
+	{N: 18, Value: 20},
+	{N: 25, Value: 23},
+	{N: 27, Value: 111},
+	{N: 54, Value: 112},
+	{N: 73, Value: 115},
+	{N: 97, Value: 118},
+	{N: 129, Value: 121},
+	{N: 171, Value: 124},
+	{N: 231, Value: 127},
+	{N: 313, Value: 130},
+	{N: 327, Value: 143},
+	{N: 649, Value: 144},
+	{N: 703, Value: 170},
+	{N: 871, Value: 178},
+	{N: 1161, Value: 181},
+	{N: 2223, Value: 182},
+	{N: 2463, Value: 208},
+	{N: 2919, Value: 216},
+	{N: 3711, Value: 237},
+	{N: 6171, Value: 261},
+	{N: 10971, Value: 267},
+	{N: 13255, Value: 275},
+	{N: 17647, Value: 278},
+	{N: 23529, Value: 281},
+	{N: 26623, Value: 307},
+	{N: 34239, Value: 310},
+	{N: 35655, Value: 323},
+	{N: 52527, Value: 339},
+	{N: 77031, Value: 350},
+	{N: 106239, Value: 353},
+	{N: 142587, Value: 374},
+	{N: 156159, Value: 382},
+	{N: 216367, Value: 385},
+	{N: 230631, Value: 442},
+	{N: 410011, Value: 448},
+	{N: 511935, Value: 469},
+	{N: 626331, Value: 508},
+	{N: 837799, Value: 524},
+	{N: 1117065, Value: 527},
+	{N: 1501353, Value: 530},
+	{N: 1723519, Value: 556},
+	{N: 2298025, Value: 559},
+	{N: 3064033, Value: 562},
+	{N: 3542887, Value: 583},
+	{N: 3732423, Value: 596},
+	{N: 5649499, Value: 612},
+	{N: 6649279, Value: 664},
+	{N: 8400511, Value: 685},
+	{N: 11200681, Value: 688},
+	{N: 14934241, Value: 691},
+	{N: 15733191, Value: 704},
+	{N: 31466382, Value: 705},
+	{N: 36791535, Value: 744},
+	{N: 63728127, Value: 949},
+}
+
+// recordsScanLimit is how far the tests scan every n for records, the records beyond it are only checked by value
+const recordsScanLimit = 1_000_000
+
+// recordTests are the published records of each type along with the value they are records of
+var recordTests = []struct {
+	name    string
+	records []Record
+	value   func(n uint64) uint64
+}{
+	{name: "delay", records: delayRecords, value: func(n uint64) uint64 { return CollatzStoppingTimeF(n).Time }},
+	{name: "path", records: PathRecords, value: func(n uint64) uint64 { return CollatzStoppingTimeF(n).Max }},
+}
+
+// publishedBelow returns the published records with n below limit
+func publishedBelow(records []Record, limit uint64) []Record {
+	i := 0
+	for i < len(records) && records[i].N < limit {
+		i++
+	}
+	return records[:i]
+}
+
+func TestRecords(t *testing.T) {
+	for _, test := range recordTests {
+		t.Run(test.name, func(t *testing.T) {
+			for _, record := range test.records {
+				r := CollatzStoppingTimeF(record.N)
+				if r.Overflow || test.value(record.N) != record.Value {
+					t.Errorf("published record n = %d has value %d but f gives %+v", record.N, record.Value, r)
+				}
+			}
+		})
+	}
+}
+
+func TestFindRecords(t *testing.T) {
+	for _, test := range recordTests {
+		published := publishedBelow(test.records, recordsScanLimit)
+		// resume after records from the start, the middle and the end of those below the limit, where resuming after
+		// n = 1 is how the records command starts
+		for _, resume := range []int{0, len(published) / 2, len(published) - 1} {
+			from, best, expected := published[resume].N+1, published[resume].Value, published[resume+1:]
+			t.Run(fmt.Sprintf("%s/from %d", test.name, from), func(t *testing.T) {
+				found := FindRecords(from, recordsScanLimit, best, test.value)
+				if len(found) != len(expected) {
+					t.Fatalf("found %d records in [%d, %d), %d are published", len(found), from, recordsScanLimit, len(expected))
+				}
+				for i, record := range found {
+					if record != expected[i] {
+						t.Fatalf("record %d is %v, expected %v", i, record, expected[i])
+					}
+				}
+				known := make([]uint64, len(test.records))
+				for i, record := range test.records {
+					known[i] = record.N
+				}
+				if matched, err := CheckRecords(found, known, from-1, recordsScanLimit); err != nil || matched != len(expected) {
+					t.Fatalf("CheckRecords = %d, %v, expected %d", matched, err, len(expected))
+				}
+			})
+		}
+	}
+}
+
+func TestCheckRecords(t *testing.T) {
+	known := []uint64{1, 2, 3, 6, 7, 9, 18, 25, 27}
+	found := []Record{{N: 1}, {N: 2}, {N: 3}, {N: 6}, {N: 7}, {N: 9}, {N: 18}, {N: 25}, {N: 27}, {N: 54}}
+	tests := []struct {
+		name    string
+		found   []Record
+		resumeN uint64
+		limit   uint64
+		matched int
+		fails   bool
+	}{
+		{name: "all", found: found, limit: 100, matched: 9},
+		{name: "below limit", found: found[:6], limit: 10, matched: 6},
+		{name: "resumed", found: found[4:], resumeN: 6, limit: 100, matched: 5},
+		{name: "resumed after unpublished n", found: found[4:], resumeN: 5, limit: 100, fails: true},
+		{name: "missing", found: append(append([]Record{}, found[:3]...), found[4:]...), limit: 100, fails: true},
+		{name: "unpublished", found: append(append([]Record{}, found[:3]...), Record{N: 5}), limit: 100, fails: true},
+		{name: "wrong n", found: append(append([]Record{}, found[:8]...), Record{N: 26}), limit: 100, fails: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			matched, err := CheckRecords(test.found, known, test.resumeN, test.limit)
+			if test.fails {
+				if err == nil {
+					t.Fatalf("CheckRecords matched %d records, expected an error", matched)
+				}
+				return
+			}
+			if err != nil || matched != test.matched {
+				t.Fatalf("CheckRecords = %d, %v, expected %d", matched, err, test.matched)
+			}
+		})
+	}
+}
+
+func TestPathRecordBound(t *testing.T) {
+	maxValue := uint64(0)
+	for n := uint64(1); n < recordsScanLimit; n++ {
+		if r := CollatzStoppingTimeF(n); r.Max > maxValue {
+			maxValue = r.Max
+		}
+		if bound, ok := PathRecordBound(n); !ok || bound != maxValue {
+			t.Fatalf("PathRecordBound(%d) = %d, %t, expected %d", n, bound, ok, maxValue)
+		}
+	}
+	if _, ok := PathRecordBound(PathRecords[len(PathRecords)-1].N); ok {
+		t.Fatal("PathRecordBound should not know the largest value beyond the last published record")
+	}
+}
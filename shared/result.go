@@ -0,0 +1,90 @@
+package shared
+
+import (
+	"math/big"
+)
+
+// Result is the outcome of following the orbit of n down to 1 with one of the stopping time functions
+type Result struct {
+	// Time is the number of steps taken by the function's own map, its total stopping time
+	Time uint64
+	// StandardTime is the number of steps the standard map C would take, the standard total stopping time
+	StandardTime uint64
+	// Max is the largest value of x passed to the function during the recursion
+	Max uint64
+	// Odd and Even are the number of odd (3x+1) and even (x/2) standard steps, so Odd + Even = StandardTime
+	Odd  uint64
+	Even uint64
+	// CoefficientTime is the coefficient stopping time of n. See countEvens.
+	CoefficientTime uint64
+	// Overflow is set when the orbit leaves the range of uint64. Every other field then only covers the orbit up to
+	// that point.
+	Overflow bool
+}
+
+// EvenPerOdd returns b/a for a odd and b even steps, or 0 if there are no odd steps
+func (r Result) EvenPerOdd() float64 {
+	if r.Odd == 0 {
+		return 0
+	}
+	return float64(r.Even) / float64(r.Odd)
+}
+
+// WideResult is Result for the arbitrary precision functions, which cannot overflow
+type WideResult struct {
+	Time            uint64
+	StandardTime    uint64
+	Max             *big.Int
+	Odd             uint64
+	Even            uint64
+	CoefficientTime uint64
+}
+
+// coefficientEvens[a] is the fewest even steps b with 2^b > 3^a, which is the bit length of 3^a
+var coefficientEvens = func() []uint64 {
+	evens := make([]uint64, 1024)
+	power := big.NewInt(1)
+	three := big.NewInt(3)
+	for a := range evens {
+		evens[a] = uint64(power.BitLen())
+		power.Mul(power, three)
+	}
+	return evens
+}()
+
+// coefficientEven returns the fewest even steps b with 3^a/2^b < 1
+func coefficientEven(a uint64) uint64 {
+	if a < uint64(len(coefficientEvens)) {
+		return coefficientEvens[a]
+	}
+	return uint64(new(big.Int).Exp(big.NewInt(3), new(big.Int).SetUint64(a), nil).BitLen())
+}
+
+// coefficientTime returns the coefficient stopping time if it falls within a run of m even steps taken after odd odd
+// steps and even even steps, or 0 otherwise.
+//
+// After a odd and b even steps x = (3^a/2^b)·n + c for some c ≥ 0 depending only on the parities so far. The
+// coefficient stopping time is the first number of standard steps a + b where the coefficient 3^a/2^b < 1, or 0 for
+// n = 1. Odd steps only increase the coefficient, so it can only drop below 1 during a run of even steps.
+func coefficientTime(odd, even, m uint64) uint64 {
+	if b := coefficientEven(odd); b <= even+m {
+		return odd + b
+	}
+	return 0
+}
+
+// countEvens adds a run of m even steps to r, setting the coefficient stopping time if it falls within the run
+func (r *Result) countEvens(m uint64) {
+	if r.CoefficientTime == 0 {
+		r.CoefficientTime = coefficientTime(r.Odd, r.Even, m)
+	}
+	r.Even += m
+}
+
+// countEvens adds a run of m even steps to r, setting the coefficient stopping time if it falls within the run
+func (r *WideResult) countEvens(m uint64) {
+	if r.CoefficientTime == 0 {
+		r.CoefficientTime = coefficientTime(r.Odd, r.Even, m)
+	}
+	r.Even += m
+}
@@ -0,0 +1,78 @@
+package shared
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"math/rand"
+)
+
+// Bucket is the range [Low, High) of n with the same magnitude in some base
+type Bucket struct {
+	Low   *big.Int
+	High  *big.Int
+	Label string
+}
+
+// MagnitudeBuckets returns the buckets [base^i, base^(i+1)) for from ≤ i < to
+func MagnitudeBuckets(base int64, from int, to int) []Bucket {
+	buckets := make([]Bucket, 0, to-from)
+	b := big.NewInt(base)
+	for i := from; i < to; i++ {
+		buckets = append(buckets, Bucket{
+			Low:   new(big.Int).Exp(b, big.NewInt(int64(i)), nil),
+			High:  new(big.Int).Exp(b, big.NewInt(int64(i+1)), nil),
+			Label: fmt.Sprintf("%d^%d..%d^%d", base, i, base, i+1),
+		})
+	}
+	return buckets
+}
+
+// Sample returns n uniform random values from the bucket
+func (b Bucket) Sample(n int, rng *rand.Rand) []*big.Int {
+	span := new(big.Int).Sub(b.High, b.Low)
+	values := make([]*big.Int, n)
+	for i := range values {
+		values[i] = new(big.Int).Rand(rng, span)
+		values[i].Add(values[i], b.Low)
+	}
+	return values
+}
+
+// MeanEstimate returns the sample mean of xs and its standard error
+func MeanEstimate(xs []float64) (float64, float64) {
+	n := float64(len(xs))
+	mean := 0.0
+	for _, x := range xs {
+		mean += x
+	}
+	mean /= n
+	variance := 0.0
+	for _, x := range xs {
+		variance += (x - mean) * (x - mean)
+	}
+	variance /= n - 1
+	return mean, math.Sqrt(variance / n)
+}
+
+// RatioEstimate returns the ratio of means Σys/Σxs of paired samples and its standard error.
+//
+// The standard error comes from the delta method, Var(ȳ/x̄) ≈ Var(y - r·x)/(n·x̄^2) where r is the estimated ratio,
+// which accounts for the strong correlation between y and x for the same n.
+func RatioEstimate(ys, xs []float64) (float64, float64) {
+	n := float64(len(xs))
+	sumY, sumX := 0.0, 0.0
+	for i := range xs {
+		sumY += ys[i]
+		sumX += xs[i]
+	}
+	ratio := sumY / sumX
+	meanX := sumX / n
+	variance := 0.0
+	for i := range xs {
+		d := ys[i] - ratio*xs[i]
+		variance += d * d
+	}
+	variance /= n - 1
+	return ratio, math.Sqrt(variance/n) / meanX
+}
@@ -1,36 +1,50 @@
 package shared
 
-// CollatzStoppingTimeF returns the result of the following recursive function f : N → N, as well as
-// the standard total stopping time, as well as the largest value of x passed to f during the recursion.
+import (
+	"math"
+	"math/bits"
+)
+
+// CollatzStoppingTimeF returns the result of the following recursive function f : N → N, along with the standard
+// total stopping time, the largest value of x passed to f during the recursion and the other fields of Result.
 //
 // f(x) = { 0                 if x = 1
 // .... = { 1 + f(C(x))       if x != 1
 //
 // # See README for explanation of C
 //
-// It returns the total stopping time twice to match the signature of the optimized functions, as well as the largest
-// value of x passed to f recursively.
+// The total stopping time and the standard total stopping time are the same for f, they are both set to match the
+// optimized functions.
 //
 // https://oeis.org/A006577
-func CollatzStoppingTimeF(n uint64) (uint64, uint64, uint64) {
-	time := uint64(0)
-	maxN := n
+func CollatzStoppingTimeF(n uint64) Result {
+	r := Result{Max: n}
 	for n != 1 {
 		if n&1 == 1 {
+			if n > maxOdd {
+				r.Overflow = true
+				break
+			}
 			n = n<<1 + n + 1 // 3n+1
+			r.Odd++
 		} else {
 			n >>= 1 // n/2
+			r.countEvens(1)
 		}
-		if n > maxN {
-			maxN = n
+		if n > r.Max {
+			r.Max = n
 		}
-		time++
+		r.Time++
 	}
-	return time, time, maxN
+	r.StandardTime = r.Time
+	return r
 }
 
-// CollatzStoppingTimeG returns the result of the following recursive function g : N → N, as well as
-// the standard total stopping time, as well as the largest value of x passed to g during the recursion.
+// maxOdd is the largest odd n where 3n+1 does not overflow
+const maxOdd = (math.MaxUint64 - 1) / 3
+
+// CollatzStoppingTimeG returns the result of the following recursive function g : N → N, along with the standard
+// total stopping time, the largest value of x passed to g during the recursion and the other fields of Result.
 //
 // g(x) = { 0                  if x = 1
 // .... = { 1 + g(x/2^m)       if x ≡ 0 (mod 2)
@@ -39,34 +53,45 @@ func CollatzStoppingTimeF(n uint64) (uint64, uint64, uint64) {
 // Where $R$ is defined in the README and 2^m is highest power of 2 that divides x
 //
 // https://oeis.org/A286380
-func CollatzStoppingTimeG(n uint64) (uint64, uint64, uint64) {
-	maxN := n
-	reducedTime, normalTime := uint64(0), uint64(0)
+func CollatzStoppingTimeG(n uint64) Result {
+	r := Result{Max: n}
 	// the main loop assumes we have an odd number
 	if n&1 == 0 {
-		for n&1 == 0 { // n/2^m
-			n >>= 1
-			normalTime++
-		}
-		reducedTime++
+		m := uint64(bits.TrailingZeros64(n))
+		n >>= m // n/2^m
+		r.StandardTime += m
+		r.countEvens(m)
+		r.Time++
 	}
 	for n != 1 {
-		n = n<<1 + n + 1 // 3n+1
-		normalTime++
-		for n&1 == 0 { // n/2^m
-			n >>= 1
-			normalTime++
+		if n > maxOdd {
+			r.Overflow = true
+			break
 		}
-		reducedTime++
-		if n > maxN {
-			maxN = n
+		n = n<<1 + n + 1 // 3n+1
+		m := uint64(bits.TrailingZeros64(n))
+		n >>= m // n/2^m
+		r.StandardTime += 1 + m
+		r.Odd++
+		r.countEvens(m)
+		r.Time++
+		if n > r.Max {
+			r.Max = n
 		}
 	}
-	return reducedTime, normalTime, maxN
+	return r
 }
 
-// CollatzStoppingTimeH returns the result of the following recursive function h : N → N, as well as
-// the standard total stopping time, as well as the largest value of x passed to h during the recursion.
+// R returns R(n) = (3n+1)/2^m for odd n, where 2^m is the highest power of 2 that divides 3n+1, along with the 1 + m
+// standard steps it jumps ahead in the orbit.
+func R(n uint64) (uint64, uint64) {
+	n = n<<1 + n + 1 // 3n+1
+	m := uint64(bits.TrailingZeros64(n))
+	return n >> m, 1 + m
+}
+
+// CollatzStoppingTimeH returns the result of the following recursive function h : N → N, along with the standard
+// total stopping time, the largest value of x passed to h during the recursion and the other fields of Result.
 //
 // h(x) = { 0              if x = 1
 // .... = { 1 + h(x/2^m)   if x ≡ 0 (mod 2)
@@ -75,32 +100,63 @@ func CollatzStoppingTimeG(n uint64) (uint64, uint64, uint64) {
 // Where R' is defined in the README and 2^m is the highest power of 2 that divides x
 //
 // https://oeis.org/A160541
-func CollatzStoppingTimeH(n uint64) (uint64, uint64, uint64) {
-	maxN := n
-	reducedTime, normalTime := uint64(0), uint64(0)
+func CollatzStoppingTimeH(n uint64) Result {
+	r := Result{Max: n}
 	// the main loop assumes we have an odd number
 	if n&1 == 0 {
-		for n&1 == 0 { // n/2^m
-			n >>= 1
-			normalTime++
-		}
-		reducedTime++
+		m := uint64(bits.TrailingZeros64(n))
+		n >>= m // n/2^m
+		r.StandardTime += m
+		r.countEvens(m)
+		r.Time++
 	}
 	for n != 1 {
-		// multiply (n+1) by (3/2) until (n+1) is no longer divisible by 2
-		for n&1 == 1 {
-			n += (n >> 1) + 1
-			normalTime += 2
-		}
-		// divide n by 2 until n is no longer divisible by 2
-		for n&1 == 0 { // n/2^m
-			n >>= 1
-			normalTime++
+		// each of the v multiplications by 3/2 is an odd and an even step
+		next, v, m, ok := RPrime(n)
+		if !ok {
+			r.Overflow = true
+			break
 		}
-		if n > maxN {
-			maxN = n
+		n = next
+		r.StandardTime += 2*v + m
+		r.Odd += v
+		r.Even += v
+		r.countEvens(m)
+		if n > r.Max {
+			r.Max = n
 		}
-		reducedTime++
+		r.Time++
 	}
-	return reducedTime, normalTime, maxN
+	return r
 }
+
+// RPrime returns R′(n) for odd n, along with v = ν2(n+1) and m, for the 2v + m standard steps it jumps ahead in the
+// orbit. It returns false if the step leaves the range of uint64.
+//
+// R′(x) = ((3/2)^ν2(x+1) (x+1) - 1)/2^m
+//
+// Where 2^m is the highest power of 2 that divides the numerator. Writing x+1 = 2^v·y, the numerator is 3^v·y - 1, so
+// the v multiplications by 3/2 are a single multiplication, and it is the largest value reached during the step. See
+// RPrimeRat for a literal implementation.
+//
+// For even n, v = 0 and it returns n/2^m, so it is the step of h for any n.
+func RPrime(n uint64) (uint64, uint64, uint64, bool) {
+	v := uint64(bits.TrailingZeros64(^n)) // ν2(n+1)
+	hi, lo := bits.Mul64((n>>v)+1, powersOf3[v])
+	if hi != 0 || lo == 0 {
+		return 0, v, 0, false
+	}
+	n = lo - 1
+	m := uint64(bits.TrailingZeros64(n))
+	return n >> m, v, m, true
+}
+
+// powersOf3 are 3^0..3^40, every power of 3 that fits in a uint64, followed by 0 up to 3^64 so that any ν2(x+1) of
+// a uint64 can be looked up
+var powersOf3 = func() [65]uint64 {
+	powers := [65]uint64{1}
+	for i := 1; i <= 40; i++ {
+		powers[i] = powers[i-1] * 3
+	}
+	return powers
+}()
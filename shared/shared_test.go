@@ -0,0 +1,343 @@
+package shared
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+// stoppingTimes are f(n), g(n) and h(n) from A006577, A286380 and A160541, along with the largest value reached by the
+// trajectory of n under C, from A025586
+var stoppingTimes = []struct {
+	n       uint64
+	f, g, h uint64
+	max     uint64
+}{
+	{n: 1, f: 0, g: 0, h: 0, max: 1},
+	{n: 2, f: 1, g: 1, h: 1, max: 2},
+	{n: 3, f: 7, g: 2, h: 1, max: 16},
+	{n: 6, f: 8, g: 3, h: 2, max: 16},
+	{n: 7, f: 16, g: 5, h: 3, max: 52},
+	{n: 9, f: 19, g: 6, h: 4, max: 52},
+	{n: 27, f: 111, g: 41, h: 17, max: 9232},
+	{n: 97, f: 118, g: 43, h: 19, max: 9232},
+	{n: 703, f: 170, g: 62, h: 25, max: 250504},
+	{n: 871, f: 178, g: 65, h: 27, max: 190996},
+	{n: 6171, f: 261, g: 96, h: 42, max: 975400},
+	{n: 77031, f: 350, g: 129, h: 53, max: 21933016},
+	{n: 837799, f: 524, g: 195, h: 81, max: 2974984576},
+	{n: 63728127, f: 949, g: 357, h: 149, max: 966616035460},
+}
+
+func TestCollatzStoppingTime(t *testing.T) {
+	for _, test := range stoppingTimes {
+		t.Run(fmt.Sprint(test.n), func(t *testing.T) {
+			f, g, h := CollatzStoppingTimeF(test.n), CollatzStoppingTimeG(test.n), CollatzStoppingTimeH(test.n)
+			if f.Overflow || f.Time != test.f || f.StandardTime != test.f || f.Max != test.max {
+				t.Errorf("f(%d) = %+v, expected time %d and max %d", test.n, f, test.f, test.max)
+			}
+			if g.Overflow || g.Time != test.g || g.StandardTime != test.f {
+				t.Errorf("g(%d) = %+v, expected time %d and standard time %d", test.n, g, test.g, test.f)
+			}
+			if h.Overflow || h.Time != test.h || h.StandardTime != test.f {
+				t.Errorf("h(%d) = %+v, expected time %d and standard time %d", test.n, h, test.h, test.f)
+			}
+			if a := A160541(test.n); a != test.h {
+				t.Errorf("A160541(%d) = %d, expected %d", test.n, a, test.h)
+			}
+		})
+	}
+}
+
+// checkProperties checks that f, g and h agree on n: they take the same odd and even standard steps, each visits a
+// subset of the values of the one before, and none overflows unless the trajectory under C leaves the range of uint64
+func checkProperties(t *testing.T, n uint64) {
+	f, g, h := CollatzStoppingTimeF(n), CollatzStoppingTimeG(n), CollatzStoppingTimeH(n)
+	if f.Overflow {
+		return
+	}
+	// every value of g and h is on the trajectory under C, so they cannot overflow when f does not
+	for _, r := range []Result{f, g, h} {
+		if r.Overflow {
+			t.Fatalf("g or h overflows for %d but f does not: %+v, %+v, %+v", n, f, g, h)
+		}
+		if r.StandardTime != f.StandardTime || r.Odd != f.Odd || r.Even != f.Even || r.CoefficientTime != f.CoefficientTime {
+			t.Fatalf("f, g and h take different standard steps for %d: %+v, %+v, %+v", n, f, g, h)
+		}
+		if r.Odd+r.Even != r.StandardTime {
+			t.Fatalf("odd and even steps do not add up to the standard steps for %d: %+v", n, r)
+		}
+		if r.Max < n {
+			t.Fatalf("max is below %d: %+v", n, r)
+		}
+	}
+	if f.Max < g.Max || g.Max < h.Max {
+		t.Fatalf("f, g and h do not visit subsets of each other's values for %d: %+v, %+v, %+v", n, f, g, h)
+	}
+	if f.Time < g.Time || g.Time < h.Time {
+		t.Fatalf("f, g and h do not take fewer steps in turn for %d: %+v, %+v, %+v", n, f, g, h)
+	}
+}
+
+func TestProperties(t *testing.T) {
+	for n := uint64(1); n < 1<<16; n++ {
+		checkProperties(t, n)
+	}
+	for _, bitLen := range benchmarkMagnitudes {
+		for _, n := range benchmarkInputs(bitLen, 1<<10) {
+			checkProperties(t, n)
+		}
+	}
+}
+
+// checkWide checks a Result against the WideResult of the same n, or that the trajectory under C leaves the range of
+// uint64 if the Result overflows
+func checkWide(t *testing.T, name string, n uint64, r Result, wide WideResult) {
+	if r.Overflow {
+		if f := CollatzStoppingTimeFWide(new(big.Int).SetUint64(n)); f.Max.BitLen() <= 64 {
+			t.Fatalf("%s(%d) overflows but the trajectory stays below 2^64: %+v", name, n, r)
+		}
+		return
+	}
+	if !wide.Max.IsUint64() || r.Max != wide.Max.Uint64() || r.Time != wide.Time || r.StandardTime != wide.StandardTime ||
+		r.Odd != wide.Odd || r.Even != wide.Even || r.CoefficientTime != wide.CoefficientTime {
+		t.Fatalf("%s(%d) = %+v but the wide version gives %+v", name, n, r, wide)
+	}
+}
+
+// fuzzSeeds are the inputs every fuzz target starts from, including the largest odd n where 3n+1 fits and the n
+// around it
+var fuzzSeeds = []uint64{1, 2, 3, 27, 837799, 63728127, 1<<40 - 1, maxOdd - 2, maxOdd, maxOdd + 2, 1<<63 + 1, math.MaxUint64}
+
+func FuzzCollatzStoppingTimeF(f *testing.F) {
+	for _, n := range fuzzSeeds {
+		f.Add(n)
+	}
+	f.Fuzz(func(t *testing.T, n uint64) {
+		if n == 0 {
+			return
+		}
+		checkWide(t, "f", n, CollatzStoppingTimeF(n), CollatzStoppingTimeFWide(new(big.Int).SetUint64(n)))
+		checkProperties(t, n)
+	})
+}
+
+func FuzzCollatzStoppingTimeG(f *testing.F) {
+	for _, n := range fuzzSeeds {
+		f.Add(n)
+	}
+	f.Fuzz(func(t *testing.T, n uint64) {
+		if n == 0 {
+			return
+		}
+		checkWide(t, "g", n, CollatzStoppingTimeG(n), CollatzStoppingTimeGWide(new(big.Int).SetUint64(n)))
+		checkProperties(t, n)
+	})
+}
+
+func FuzzCollatzStoppingTimeH(f *testing.F) {
+	for _, n := range fuzzSeeds {
+		f.Add(n)
+	}
+	f.Fuzz(func(t *testing.T, n uint64) {
+		if n == 0 {
+			return
+		}
+		h := CollatzStoppingTimeH(n)
+		checkWide(t, "h", n, h, CollatzStoppingTimeHWide(new(big.Int).SetUint64(n)))
+		checkProperties(t, n)
+		if !CollatzStoppingTimeF(n).Overflow {
+			if a := A160541(n); a != h.Time {
+				t.Fatalf("h(%d) = %d but A160541 gives %d", n, h.Time, a)
+			}
+		}
+	})
+}
+
+// fuzzInput returns the positive n given by big-endian bytes, up to 2^256 to keep each input fast, or false
+func fuzzInput(b []byte) (*big.Int, bool) {
+	if len(b) > 32 {
+		b = b[:32]
+	}
+	n := new(big.Int).SetBytes(b)
+	return n, n.Sign() > 0
+}
+
+// checkWideStep checks the recursion r(n) = 1 + r(next) of one of the wide functions, where next is the value after
+// one step of its map covering the given standard steps, along with the properties shared by all of them
+func checkWideStep(t *testing.T, name string, n *big.Int, r WideResult, next *big.Int, steps uint64, fn func(n *big.Int) WideResult) {
+	if r.Odd+r.Even != r.StandardTime || r.Max.Cmp(n) < 0 || r.Time > r.StandardTime {
+		t.Fatalf("%s(%s) = %+v", name, n, r)
+	}
+	if n.Cmp(big.NewInt(1)) == 0 {
+		if r.Time != 0 || r.StandardTime != 0 {
+			t.Fatalf("%s(1) = %+v, expected 0", name, r)
+		}
+		return
+	}
+	rest := fn(next)
+	if r.Time != 1+rest.Time || r.StandardTime != steps+rest.StandardTime {
+		t.Fatalf("%s(%s) = %+v but %s(%s) = %+v after %d standard steps", name, n, r, name, next, rest, steps)
+	}
+	if f := CollatzStoppingTimeFWide(n); r.StandardTime != f.StandardTime || r.Odd != f.Odd || r.CoefficientTime != f.CoefficientTime {
+		t.Fatalf("%s(%s) = %+v but f takes %+v", name, n, r, f)
+	}
+}
+
+// wideFuzzSeeds are big-endian inputs for the wide fuzz targets, up to 2^128
+var wideFuzzSeeds = [][]byte{
+	{1}, {2}, {27},
+	{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+	{0x01, 0, 0, 0, 0, 0, 0, 0, 0x01},
+	{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+}
+
+func FuzzCollatzStoppingTimeFWide(f *testing.F) {
+	for _, b := range wideFuzzSeeds {
+		f.Add(b)
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		n, ok := fuzzInput(b)
+		if !ok {
+			return
+		}
+		next := new(big.Int)
+		if n.Bit(0) == 1 {
+			next.Add(next.Add(next.Lsh(n, 1), n), big.NewInt(1)) // 3n+1
+		} else {
+			next.Rsh(n, 1) // n/2
+		}
+		r := CollatzStoppingTimeFWide(n)
+		checkWideStep(t, "f", n, r, next, 1, CollatzStoppingTimeFWide)
+		if n.IsUint64() {
+			checkWide(t, "f", n.Uint64(), CollatzStoppingTimeF(n.Uint64()), r)
+		}
+	})
+}
+
+func FuzzCollatzStoppingTimeGWide(f *testing.F) {
+	for _, b := range wideFuzzSeeds {
+		f.Add(b)
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		n, ok := fuzzInput(b)
+		if !ok {
+			return
+		}
+		next := new(big.Int).Set(n)
+		steps := uint64(0)
+		if n.Bit(0) == 1 {
+			next.Add(next.Add(next.Lsh(n, 1), n), big.NewInt(1)) // 3n+1
+			steps++
+		}
+		m := next.TrailingZeroBits()
+		next.Rsh(next, m) // x/2^m
+		r := CollatzStoppingTimeGWide(n)
+		checkWideStep(t, "g", n, r, next, steps+uint64(m), CollatzStoppingTimeGWide)
+		if n.IsUint64() {
+			checkWide(t, "g", n.Uint64(), CollatzStoppingTimeG(n.Uint64()), r)
+		}
+	})
+}
+
+func FuzzCollatzStoppingTimeHWide(f *testing.F) {
+	for _, b := range wideFuzzSeeds {
+		f.Add(b)
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		n, ok := fuzzInput(b)
+		if !ok {
+			return
+		}
+		var next *big.Int
+		var steps uint64
+		if n.Bit(0) == 1 {
+			x, v, m := RPrimeRat(n)
+			next, steps = x, uint64(2*v+m)
+		} else {
+			m := n.TrailingZeroBits()
+			next, steps = new(big.Int).Rsh(n, m), uint64(m) // n/2^m
+		}
+		r := CollatzStoppingTimeHWide(n)
+		checkWideStep(t, "h", n, r, next, steps, CollatzStoppingTimeHWide)
+		if n.IsUint64() {
+			checkWide(t, "h", n.Uint64(), CollatzStoppingTimeH(n.Uint64()), r)
+		}
+	})
+}
+
+// benchmarkInputs returns count random n of the given bit length whose orbits stay in the range of uint64
+func benchmarkInputs(bitLen uint, count int) []uint64 {
+	rng := rand.New(rand.NewSource(1))
+	inputs := make([]uint64, 0, count)
+	for len(inputs) < count {
+		n := uint64(1)<<(bitLen-1) | rng.Uint64()&(1<<(bitLen-1)-1)
+		if !CollatzStoppingTimeF(n).Overflow {
+			inputs = append(inputs, n)
+		}
+	}
+	return inputs
+}
+
+// benchmarkMagnitudes are the bit lengths of the n timed by the benchmarks
+var benchmarkMagnitudes = []uint{16, 32, 48, 60}
+
+func benchmarkFunction(b *testing.B, fn func(uint64) Result) {
+	for _, bitLen := range benchmarkMagnitudes {
+		inputs := benchmarkInputs(bitLen, 1<<10)
+		b.Run(fmt.Sprintf("2^%d", bitLen), func(b *testing.B) {
+			steps := uint64(0)
+			for i := 0; i < b.N; i++ {
+				steps += fn(inputs[i%len(inputs)]).StandardTime
+			}
+			b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(steps), "ns/step")
+		})
+	}
+}
+
+func BenchmarkCollatzStoppingTimeF(b *testing.B) {
+	benchmarkFunction(b, CollatzStoppingTimeF)
+}
+
+func BenchmarkCollatzStoppingTimeG(b *testing.B) {
+	benchmarkFunction(b, CollatzStoppingTimeG)
+}
+
+func BenchmarkCollatzStoppingTimeH(b *testing.B) {
+	benchmarkFunction(b, CollatzStoppingTimeH)
+}
+
+// wideBenchmarkMagnitudes are the bit lengths of the n timed by the wide benchmarks
+var wideBenchmarkMagnitudes = []uint{64, 128, 256}
+
+func benchmarkWideFunction(b *testing.B, fn func(*big.Int) WideResult) {
+	rng := rand.New(rand.NewSource(1))
+	for _, bitLen := range wideBenchmarkMagnitudes {
+		inputs := make([]*big.Int, 1<<8)
+		for i := range inputs {
+			inputs[i] = new(big.Int).Rand(rng, new(big.Int).Lsh(big.NewInt(1), bitLen-1))
+			inputs[i].SetBit(inputs[i], int(bitLen-1), 1)
+		}
+		b.Run(fmt.Sprintf("2^%d", bitLen), func(b *testing.B) {
+			steps := uint64(0)
+			for i := 0; i < b.N; i++ {
+				steps += fn(inputs[i%len(inputs)]).StandardTime
+			}
+			b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(steps), "ns/step")
+		})
+	}
+}
+
+func BenchmarkCollatzStoppingTimeFWide(b *testing.B) {
+	benchmarkWideFunction(b, CollatzStoppingTimeFWide)
+}
+
+func BenchmarkCollatzStoppingTimeGWide(b *testing.B) {
+	benchmarkWideFunction(b, CollatzStoppingTimeGWide)
+}
+
+func BenchmarkCollatzStoppingTimeHWide(b *testing.B) {
+	benchmarkWideFunction(b, CollatzStoppingTimeHWide)
+}
@@ -0,0 +1,100 @@
+package shared
+
+import "fmt"
+
+// Sieve is the set of residue classes b mod 2^k for which the first k steps of the Terras map do not provably drop
+// below where they started.
+//
+// Writing n = a·2^k + b, the first j ≤ k steps of T have the same parity for every a, so
+//
+// T^j(n) = 3^c·a·2^(k-j) + T^j(b)
+//
+// Where c is the number of odd steps among them. Once 3^c < 2^j the coefficient of a is below 2^k, and T^j(n) < n for
+// every a ≥ A(b), where A(b) is 0 unless T^j(b) ≥ b. Only the surviving classes, where 3^c ≥ 2^j for every j ≤ k, need
+// to be iterated to show that every n eventually drops below itself.
+type Sieve struct {
+	k          uint
+	eliminated []uint64
+	// Survivors are the residue classes b mod 2^k that are not eliminated within k steps, in increasing order
+	Survivors []uint64
+	// exceptions maps each eliminated class to the smallest a for which a·2^k + b provably drops, when it is not 0
+	exceptions map[uint64]uint64
+}
+
+// NewSieve returns the sieve of residue classes mod 2^k, where 1 ≤ k ≤ 28
+func NewSieve(k uint) *Sieve {
+	if k < 1 || k > 28 {
+		panic(fmt.Sprintf("sieve size must be in the range 1..28: %d", k))
+	}
+	size := uint64(1) << k
+	s := &Sieve{
+		k:          k,
+		eliminated: make([]uint64, (size+63)/64),
+		Survivors:  make([]uint64, 0),
+		exceptions: make(map[uint64]uint64),
+	}
+	for b := uint64(0); b < size; b++ {
+		x := b
+		pow3, pow2 := uint64(1), uint64(1)
+		survives := true
+		for j := uint(1); j <= k; j++ {
+			if x&1 == 1 {
+				x = x + x>>1 + 1 // (3x+1)/2
+				pow3 *= 3
+			} else {
+				x >>= 1 // x/2
+			}
+			pow2 <<= 1
+			if pow3 < pow2 {
+				survives = false
+				// T^j(n) < n iff T^j(b) - b < a·2^(k-j)·(2^j - 3^c)
+				if x >= b {
+					s.exceptions[b] = (x-b)/((pow2-pow3)<<(k-j)) + 1
+				}
+				break
+			}
+		}
+		if survives {
+			s.Survivors = append(s.Survivors, b)
+		} else {
+			s.eliminated[b>>6] |= 1 << (b & 63)
+		}
+	}
+	return s
+}
+
+// Bits returns k, where the residue classes are taken mod 2^k
+func (s *Sieve) Bits() uint {
+	return s.k
+}
+
+// Descends reports whether the sieve proves that the trajectory of n under T drops below n
+func (s *Sieve) Descends(n uint64) bool {
+	b := n & (1<<s.k - 1)
+	if s.eliminated[b>>6]&(1<<(b&63)) == 0 {
+		return false
+	}
+	return n>>s.k >= s.exceptions[b]
+}
+
+// DescentTime returns the number of standard steps needed for n > 1 to drop below n, whether the trajectory stayed
+// within the range of uint64, and the largest value reached along the way. See DescentTimeWide when it does not.
+func DescentTime(n uint64) (uint64, bool, uint64) {
+	x, maxX := n, n
+	steps := uint64(0)
+	for x >= n {
+		if x&1 == 1 {
+			if x > maxOdd {
+				return steps, false, maxX
+			}
+			x = x<<1 + x + 1 // 3x+1
+			if x > maxX {
+				maxX = x
+			}
+		} else {
+			x >>= 1 // x/2
+		}
+		steps++
+	}
+	return steps, true, maxX
+}
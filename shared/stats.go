@@ -0,0 +1,57 @@
+package shared
+
+import (
+	"math"
+	"math/bits"
+)
+
+// ValuationCounts is the joint distribution of v = ν2(x+1) and the trailing exponent m over steps of R′, indexed
+// [v][m]. Both are at most 64 for x in the range of uint64.
+type ValuationCounts [65][65]uint64
+
+// Add counts the v and m of every step of R′ from n down to 1. The division that makes an even n odd is not a step of
+// R′ and is not counted. It returns false if the orbit leaves the range of uint64, leaving the steps before it counted.
+func (c *ValuationCounts) Add(n uint64) bool {
+	n >>= bits.TrailingZeros64(n)
+	for n != 1 {
+		next, v, m, ok := RPrime(n)
+		if !ok {
+			return false
+		}
+		c[v][m]++
+		n = next
+	}
+	return true
+}
+
+// Merge adds the counts of other to c
+func (c *ValuationCounts) Merge(other *ValuationCounts) {
+	for v := range c {
+		for m := range c[v] {
+			c[v][m] += other[v][m]
+		}
+	}
+}
+
+// Total returns the number of steps counted
+func (c *ValuationCounts) Total() uint64 {
+	total := uint64(0)
+	for v := range c {
+		for m := range c[v] {
+			total += c[v][m]
+		}
+	}
+	return total
+}
+
+// ValuationProbability is the probability of a step of R′ having ν2(x+1) = v and trailing exponent m under the
+// heuristic model where x is a random odd number.
+//
+// x+1 is a random even number, so ν2(x+1) = v ≥ 1 with probability 2^-v. The numerator 3^v (x+1)/2^v - 1 is then a
+// random even number, so m ≥ 1 with probability 2^-m independently of v.
+func ValuationProbability(v, m int) float64 {
+	if v < 1 || m < 1 {
+		return 0
+	}
+	return math.Ldexp(1, -(v + m))
+}
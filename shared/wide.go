@@ -0,0 +1,113 @@
+package shared
+
+import "math/big"
+
+// DescentTimeWide returns the number of standard steps needed for n > 1 to drop below n, and the largest value reached
+// along the way, using arbitrary precision so the trajectory cannot overflow.
+func DescentTimeWide(n uint64) (uint64, *big.Int) {
+	start := new(big.Int).SetUint64(n)
+	x := new(big.Int).Set(start)
+	maxX := new(big.Int).Set(start)
+	one := big.NewInt(1)
+	double := new(big.Int)
+	steps := uint64(0)
+	for x.Cmp(start) >= 0 {
+		if x.Bit(0) == 1 {
+			x.Add(x.Add(x, double.Lsh(x, 1)), one) // 3x+1
+			if x.Cmp(maxX) > 0 {
+				maxX.Set(x)
+			}
+		} else {
+			x.Rsh(x, 1) // x/2
+		}
+		steps++
+	}
+	return steps, maxX
+}
+
+// CollatzStoppingTimeFWide is CollatzStoppingTimeF using arbitrary precision, for n beyond the range of uint64 or whose
+// trajectory leaves it. n must be positive and is not modified.
+func CollatzStoppingTimeFWide(n *big.Int) WideResult {
+	x := new(big.Int).Set(n)
+	r := WideResult{Max: new(big.Int).Set(n)}
+	one := big.NewInt(1)
+	double := new(big.Int)
+	for x.Cmp(one) != 0 {
+		if x.Bit(0) == 1 {
+			x.Add(x.Add(x, double.Lsh(x, 1)), one) // 3x+1
+			if x.Cmp(r.Max) > 0 {
+				r.Max.Set(x)
+			}
+			r.Odd++
+		} else {
+			x.Rsh(x, 1) // x/2
+			r.countEvens(1)
+		}
+		r.Time++
+	}
+	r.StandardTime = r.Time
+	return r
+}
+
+// CollatzStoppingTimeGWide is CollatzStoppingTimeG using arbitrary precision. n must be positive and is not modified.
+func CollatzStoppingTimeGWide(n *big.Int) WideResult {
+	x := new(big.Int).Set(n)
+	r := WideResult{Max: new(big.Int).Set(n)}
+	one := big.NewInt(1)
+	double := new(big.Int)
+	if x.Bit(0) == 0 {
+		m := uint64(x.TrailingZeroBits())
+		x.Rsh(x, uint(m)) // x/2^m
+		r.StandardTime += m
+		r.countEvens(m)
+		r.Time++
+	}
+	for x.Cmp(one) != 0 {
+		x.Add(x.Add(x, double.Lsh(x, 1)), one) // 3x+1
+		m := uint64(x.TrailingZeroBits())
+		x.Rsh(x, uint(m)) // x/2^m
+		if x.Cmp(r.Max) > 0 {
+			r.Max.Set(x)
+		}
+		r.StandardTime += 1 + m
+		r.Odd++
+		r.countEvens(m)
+		r.Time++
+	}
+	return r
+}
+
+// CollatzStoppingTimeHWide is CollatzStoppingTimeH using arbitrary precision. n must be positive and is not modified.
+//
+// Each step of R′ is computed as in the README, x+1 = 2^v·y gives 3^v·y - 1 = 2^m·R′(x), rather than one bit at a time.
+func CollatzStoppingTimeHWide(n *big.Int) WideResult {
+	x := new(big.Int).Set(n)
+	r := WideResult{Max: new(big.Int).Set(n)}
+	one := big.NewInt(1)
+	power := new(big.Int)
+	if x.Bit(0) == 0 {
+		m := uint64(x.TrailingZeroBits())
+		x.Rsh(x, uint(m)) // x/2^m
+		r.StandardTime += m
+		r.countEvens(m)
+		r.Time++
+	}
+	for x.Cmp(one) != 0 {
+		x.Add(x, one)
+		v := uint64(x.TrailingZeroBits()) // ν2(x+1)
+		x.Rsh(x, uint(v))
+		x.Mul(x, power.Exp(big.NewInt(3), new(big.Int).SetUint64(v), nil))
+		x.Sub(x, one)
+		m := uint64(x.TrailingZeroBits())
+		x.Rsh(x, uint(m)) // x/2^m
+		if x.Cmp(r.Max) > 0 {
+			r.Max.Set(x)
+		}
+		r.StandardTime += 2*v + m
+		r.Odd += v
+		r.Even += v
+		r.countEvens(m)
+		r.Time++
+	}
+	return r
+}